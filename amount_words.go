@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type wordForms struct {
+	one, few, many string
+	feminine       bool
+}
+
+type currencyWords struct {
+	major wordForms
+	minor wordForms
+}
+
+var currencyWordForms = map[string]currencyWords{
+	"RUB": {wordForms{"рубль", "рубля", "рублей", false}, wordForms{"копейка", "копейки", "копеек", true}},
+	"USD": {wordForms{"доллар США", "доллара США", "долларов США", false}, wordForms{"цент", "цента", "центов", false}},
+	"EUR": {wordForms{"евро", "евро", "евро", false}, wordForms{"евроцент", "евроцента", "евроцентов", false}},
+	"CNY": {wordForms{"юань", "юаня", "юаней", false}, wordForms{"фэнь", "фэня", "фэней", false}},
+}
+
+var (
+	unitWords     = []string{"", "один", "два", "три", "четыре", "пять", "шесть", "семь", "восемь", "девять"}
+	unitWordsFem  = []string{"", "одна", "две", "три", "четыре", "пять", "шесть", "семь", "восемь", "девять"}
+	teenWords     = []string{"десять", "одиннадцать", "двенадцать", "тринадцать", "четырнадцать", "пятнадцать", "шестнадцать", "семнадцать", "восемнадцать", "девятнадцать"}
+	tensWords     = []string{"", "", "двадцать", "тридцать", "сорок", "пятьдесят", "шестьдесят", "семьдесят", "восемьдесят", "девяносто"}
+	hundredsWords = []string{"", "сто", "двести", "триста", "четыреста", "пятьсот", "шестьсот", "семьсот", "восемьсот", "девятьсот"}
+	scaleForms    = []wordForms{
+		{"тысяча", "тысячи", "тысяч", true},
+		{"миллион", "миллиона", "миллионов", false},
+		{"миллиард", "миллиарда", "миллиардов", false},
+		{"триллион", "триллиона", "триллионов", false},
+		{"квадриллион", "квадриллиона", "квадриллионов", false},
+	}
+)
+
+func (f wordForms) pick(n int64) string {
+	switch {
+	case n%100 >= 11 && n%100 <= 14:
+		return f.many
+	case n%10 == 1:
+		return f.one
+	case n%10 >= 2 && n%10 <= 4:
+		return f.few
+	default:
+		return f.many
+	}
+}
+
+func tripletWords(n int64, feminine bool) []string {
+	var words []string
+	if h := n / 100; h > 0 {
+		words = append(words, hundredsWords[h])
+	}
+	rest := n % 100
+	switch {
+	case rest >= 10 && rest < 20:
+		words = append(words, teenWords[rest-10])
+		return words
+	case rest >= 20:
+		words = append(words, tensWords[rest/10])
+		rest %= 10
+	}
+	if rest > 0 {
+		if feminine {
+			words = append(words, unitWordsFem[rest])
+		} else {
+			words = append(words, unitWords[rest])
+		}
+	}
+	return words
+}
+
+func numberToWords(n int64, feminine bool) string {
+	if n == 0 {
+		return "ноль"
+	}
+	var groups []int64
+	for value := n; value > 0; value /= 1000 {
+		groups = append(groups, value%1000)
+	}
+	var words []string
+	for i := len(groups) - 1; i >= 0; i-- {
+		group := groups[i]
+		if group == 0 {
+			continue
+		}
+		if i == 0 {
+			words = append(words, tripletWords(group, feminine)...)
+			continue
+		}
+		scale := scaleForms[i-1]
+		words = append(words, tripletWords(group, scale.feminine)...)
+		words = append(words, scale.pick(group))
+	}
+	return strings.Join(words, " ")
+}
+
+func capitalizeFirst(text string) string {
+	r, size := utf8.DecodeRuneInString(text)
+	if r == utf8.RuneError {
+		return text
+	}
+	return string(unicode.ToUpper(r)) + text[size:]
+}
+
+func amountInWords(amount Money) string {
+	kopecks := amount.Kopecks
+	sign := ""
+	if kopecks < 0 {
+		sign = "минус "
+		kopecks = -kopecks
+	}
+	if kopecks < 0 {
+		return amount.String()
+	}
+	major, minor := kopecks/100, kopecks%100
+	forms, ok := currencyWordForms[amount.Currency]
+	if !ok {
+		return capitalizeFirst(fmt.Sprintf("%s%s %s %02d/100", sign, numberToWords(major, false), amount.Currency, minor))
+	}
+	return capitalizeFirst(fmt.Sprintf("%s%s %s %02d %s", sign, numberToWords(major, forms.major.feminine), forms.major.pick(major), minor, forms.minor.pick(minor)))
+}
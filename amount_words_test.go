@@ -0,0 +1,35 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAmountInWords(t *testing.T) {
+	tests := []struct {
+		amount Money
+		want   string
+	}{
+		{Money{Kopecks: 5, Currency: "RUB"}, "Ноль рублей 05 копеек"},
+		{Money{Kopecks: 101, Currency: "RUB"}, "Один рубль 01 копейка"},
+		{Money{Kopecks: 222, Currency: "RUB"}, "Два рубля 22 копейки"},
+		{Money{Kopecks: 1100, Currency: "RUB"}, "Одиннадцать рублей 00 копеек"},
+		{Money{Kopecks: 2100, Currency: "RUB"}, "Двадцать один рубль 00 копеек"},
+		{Money{Kopecks: 200100, Currency: "RUB"}, "Две тысячи один рубль 00 копеек"},
+		{Money{Kopecks: 123456789, Currency: "RUB"}, "Один миллион двести тридцать четыре тысячи пятьсот шестьдесят семь рублей 89 копеек"},
+		{Money{Kopecks: 10000000000000000, Currency: "RUB"}, "Сто триллионов рублей 00 копеек"},
+		{Money{Kopecks: 100000000000000000, Currency: "RUB"}, "Один квадриллион рублей 00 копеек"},
+		{Money{Kopecks: math.MaxInt64, Currency: "RUB"}, "Девяносто два квадриллиона двести тридцать три триллиона семьсот двадцать миллиардов триста шестьдесят восемь миллионов пятьсот сорок семь тысяч семьсот пятьдесят восемь рублей 07 копеек"},
+		{Money{Kopecks: -math.MaxInt64, Currency: "USD"}, "Минус девяносто два квадриллиона двести тридцать три триллиона семьсот двадцать миллиардов триста шестьдесят восемь миллионов пятьсот сорок семь тысяч семьсот пятьдесят восемь долларов США 07 центов"},
+		{Money{Kopecks: math.MinInt64, Currency: "RUB"}, Money{Kopecks: math.MinInt64, Currency: "RUB"}.String()},
+		{Money{Kopecks: 150, Currency: "EUR"}, "Один евро 50 евроцентов"},
+		{Money{Kopecks: 200000, Currency: "USD"}, "Две тысячи долларов США 00 центов"},
+		{Money{Kopecks: -150, Currency: "CNY"}, "Минус один юань 50 фэней"},
+		{Money{Kopecks: 150, Currency: "KZT"}, "Один KZT 50/100"},
+	}
+	for _, tt := range tests {
+		if got := amountInWords(tt.amount); got != tt.want {
+			t.Errorf("amountInWords(%d %s) = %q, ожидалось %q", tt.amount.Kopecks, tt.amount.Currency, got, tt.want)
+		}
+	}
+}
@@ -4,15 +4,12 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"log"
 	"math/rand"
 	"os"
 	"regexp"
-	"sort"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -23,12 +20,65 @@ import (
 	"github.com/unidoc/unioffice/v2/document"
 	"github.com/unidoc/unioffice/v2/measurement"
 	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 type Config struct {
-	UseGigaChat bool           `json:"useGigaChat"`
-	GigaChat    GigaChatConfig `json:"gigaChat"`
-	Ollama      OllamaConfig   `json:"ollama"`
+	UseGigaChat bool             `json:"useGigaChat,omitempty"`
+	Provider    string           `json:"provider"`
+	GigaChat    GigaChatConfig   `json:"gigaChat"`
+	Ollama      OllamaConfig     `json:"ollama"`
+	OpenAI      OpenAIConfig     `json:"openai"`
+	Fallback    FallbackConfig   `json:"fallback"`
+	LLM         LLMConfig        `json:"llm"`
+	Catalog     CatalogConfig    `json:"catalog"`
+	VAT         VATConfig        `json:"vat"`
+	Pricing     PricingConfig    `json:"pricing"`
+	Currency    CurrencyConfig   `json:"currency"`
+	Embeddings  EmbeddingsConfig `json:"embeddings"`
+}
+type CatalogConfig struct {
+	Source        string         `json:"source"`
+	Sheet         string         `json:"sheet"`
+	Offers        string         `json:"offers"`
+	PriceType     string         `json:"priceType"`
+	CostPriceType string         `json:"costPriceType"`
+	Delimiter     string         `json:"delimiter"`
+	Encoding      string         `json:"encoding"`
+	Currency      string         `json:"currency"`
+	Columns       CatalogColumns `json:"columns"`
+	LLMFallback   bool           `json:"llmFallback"`
+	LLMChunkLines int            `json:"llmChunkLines"`
+	LLMWorkers    int            `json:"llmWorkers"`
+}
+type CatalogColumns struct {
+	Name     string `json:"name"`
+	Article  string `json:"article"`
+	Price    string `json:"price"`
+	Unit     string `json:"unit"`
+	Category string `json:"category"`
+	Stock    string `json:"stock"`
+	PackSize string `json:"packSize"`
+	VATRate  string `json:"vatRate"`
+	Cost     string `json:"cost"`
+}
+type LLMConfig struct {
+	Timeouts       TimeoutConfig `json:"timeouts"`
+	Retry          RetryConfig   `json:"retry"`
+	StreamPlanning bool          `json:"streamPlanning"`
+}
+type TimeoutConfig struct {
+	DefaultSeconds        int `json:"defaultSeconds"`
+	KeywordsSeconds       int `json:"keywordsSeconds"`
+	PlanningSeconds       int `json:"planningSeconds"`
+	FormattingSeconds     int `json:"formattingSeconds"`
+	CatalogParsingSeconds int `json:"catalogParsingSeconds"`
+}
+type RetryConfig struct {
+	MaxAttempts       int   `json:"maxAttempts"`
+	BaseDelayMs       int   `json:"baseDelayMs"`
+	MaxDelayMs        int   `json:"maxDelayMs"`
+	RetryableStatuses []int `json:"retryableStatuses"`
 }
 type GigaChatConfig struct {
 	APIKey string `json:"apiKey"`
@@ -38,35 +88,18 @@ type OllamaConfig struct {
 	BaseURL string `json:"baseURL"`
 	Model   string `json:"model"`
 }
-
-type GigaChatMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
-}
-type GigaChatRequest struct {
-	Model       string            `json:"model"`
-	Messages    []GigaChatMessage `json:"messages"`
-	Temperature float32           `json:"temperature"`
-}
-type GigaChatResponseChoice struct {
-	Message GigaChatMessage `json:"message"`
-}
-type GigaChatResponse struct {
-	Choices []GigaChatResponseChoice `json:"choices"`
+type FallbackConfig struct {
+	Providers        []string `json:"providers"`
+	CooldownSeconds  int      `json:"cooldownSeconds"`
+	FailureThreshold int      `json:"failureThreshold"`
 }
-type OllamaRequest struct {
-	Model    string            `json:"model"`
-	Messages []GigaChatMessage `json:"messages"`
-	Stream   bool              `json:"stream"`
-}
-type OllamaResponse struct {
-	Message GigaChatMessage `json:"message"`
+type OpenAIConfig struct {
+	BaseURL  string `json:"baseURL"`
+	APIKey   string `json:"apiKey"`
+	Model    string `json:"model"`
+	JSONMode bool   `json:"jsonMode"`
 }
 
-type ParsedProduct struct {
-	Name  string `json:"name"`
-	Price int    `json:"price"`
-}
 type LLMResponseItem struct {
 	ID       int `json:"id"`
 	Quantity int `json:"quantity"`
@@ -75,42 +108,80 @@ type LLMResponse struct {
 	FoundItems []LLMResponseItem `json:"found_items"`
 }
 type TCPItem struct {
-	Name     string `json:"name"`
-	Quantity int    `json:"quantity"`
-	Price    int    `json:"price"`
-	Subtotal int    `json:"subtotal"`
+	ProductID  int      `json:"product_id,omitempty"`
+	ProductKey string   `json:"product_key,omitempty"`
+	Article    string   `json:"article,omitempty"`
+	Name       string   `json:"name"`
+	Unit       string   `json:"unit,omitempty"`
+	Category   string   `json:"category,omitempty"`
+	Quantity   int      `json:"quantity"`
+	BasePrice  Money    `json:"base_price"`
+	Discount   float64  `json:"discount_percent,omitempty"`
+	Price      Money    `json:"price"`
+	Rule       string   `json:"pricing_rule,omitempty"`
+	Stock      *float64 `json:"stock,omitempty"`
+	Subtotal   Money    `json:"subtotal"`
+	VATRate    int      `json:"vat_rate"`
+	VATAmount  Money    `json:"vat_amount"`
+	Total      Money    `json:"total"`
 }
 type Product struct {
-	ID    int    `json:"id"`
-	Name  string `json:"name"`
-	Price int    `json:"price"`
+	ID         int      `json:"id"`
+	Key        string   `json:"key,omitempty"`
+	ExternalID string   `json:"externalId,omitempty"`
+	Name       string   `json:"name"`
+	Article    string   `json:"article,omitempty"`
+	Unit       string   `json:"unit,omitempty"`
+	Category   string   `json:"category,omitempty"`
+	Price      Money    `json:"price"`
+	Cost       *Money   `json:"cost,omitempty"`
+	Stock      *float64 `json:"stock,omitempty"`
+	PackSize   float64  `json:"packSize,omitempty"`
+	VATRate    *int     `json:"vatRate,omitempty"`
+	SourceLine int      `json:"sourceLine,omitempty"`
 }
 type FinalLogResponse struct {
-	FoundItems []TCPItem `json:"found_items"`
-	TotalCost  int       `json:"total_cost"`
+	FoundItems       []TCPItem     `json:"found_items"`
+	TotalCost        Money         `json:"total_cost"`
+	TotalVAT         Money         `json:"total_vat"`
+	TotalWithoutVAT  Money         `json:"total_without_vat"`
+	PricesIncludeVAT bool          `json:"prices_include_vat"`
+	Currency         string        `json:"currency"`
+	ExchangeRates    []AppliedRate `json:"exchange_rates,omitempty"`
 }
 type LogEntry struct {
-	Query    string           `json:"query"`
-	Response FinalLogResponse `json:"response"`
+	Query     string            `json:"query"`
+	Customer  string            `json:"customer,omitempty"`
+	Overrides []PriceOverride   `json:"overrides,omitempty"`
+	Providers map[string]string `json:"providers,omitempty"`
+	Response  FinalLogResponse  `json:"response"`
 }
 
 type App struct {
-	ctx            context.Context
-	config         Config
-	products       []Product
-	productMap     map[int]Product
-	httpClient     *resty.Client
-	gigaToken      string
-	tokenExpiresAt time.Time
-	tokenMutex     sync.Mutex
-	jsonExtractor  *regexp.Regexp
-	dataLoadMutex  sync.Mutex
+	ctx           context.Context
+	config        Config
+	catalog       *catalogSnapshot
+	catalogMutex  sync.RWMutex
+	vectors       *vectorIndex
+	vectorsMutex  sync.RWMutex
+	vectorsCancel context.CancelFunc
+	vectorsBuild  sync.Mutex
+	synonyms      SynonymDictionary
+	synonymsMutex sync.RWMutex
+	httpClient    *resty.Client
+	llm           LLMProvider
+	jsonExtractor *regexp.Regexp
+	dataLoadMutex sync.Mutex
+	jobs          map[string]*generationJob
+	jobsMutex     sync.Mutex
+	eventSink     func(eventName string, data ...interface{})
 }
 
 func createDefaultConfig() (Config, error) {
 	log.Println("Файл 'config.json' не найден. Создаю файл с настройками по умолчанию...")
+	pricesIncludeVAT := true
 	defaultConfig := Config{
-		UseGigaChat: true,
+		Provider: "gigachat",
 		GigaChat: GigaChatConfig{
 			APIKey: "PASTE_YOUR_BASE64_GIGACHAT_API_KEY_HERE",
 			Model:  "GigaChat:latest",
@@ -119,6 +190,56 @@ func createDefaultConfig() (Config, error) {
 			BaseURL: "http://localhost:11434",
 			Model:   "llama3",
 		},
+		OpenAI: OpenAIConfig{
+			BaseURL: "http://localhost:8000/v1",
+			Model:   "local-model",
+		},
+		Fallback: FallbackConfig{
+			Providers:        []string{"ollama"},
+			CooldownSeconds:  120,
+			FailureThreshold: 1,
+		},
+		LLM: LLMConfig{
+			Timeouts: TimeoutConfig{
+				DefaultSeconds:        120,
+				KeywordsSeconds:       30,
+				PlanningSeconds:       180,
+				FormattingSeconds:     120,
+				CatalogParsingSeconds: 600,
+			},
+			Retry: RetryConfig{
+				MaxAttempts:       3,
+				BaseDelayMs:       1000,
+				MaxDelayMs:        30000,
+				RetryableStatuses: defaultRetryableStatuses,
+			},
+			StreamPlanning: true,
+		},
+		Catalog: CatalogConfig{
+			Source:        "materials.csv",
+			Delimiter:     "auto",
+			Encoding:      "auto",
+			LLMFallback:   true,
+			LLMChunkLines: 80,
+			LLMWorkers:    3,
+			Currency:      defaultCurrency,
+		},
+		VAT: VATConfig{
+			PricesIncludeVAT: &pricesIncludeVAT,
+		},
+		Currency: CurrencyConfig{
+			Default:    defaultCurrency,
+			RatesFile:  defaultRatesFile,
+			MaxAgeDays: defaultRatesMaxAgeDays,
+		},
+		Embeddings: EmbeddingsConfig{
+			Model:          defaultEmbeddingsModel,
+			IndexFile:      defaultEmbeddingsFile,
+			Weight:         defaultEmbeddingsWeight,
+			MinSimilarity:  defaultEmbeddingsMinScore,
+			Workers:        defaultEmbeddingsWorkers,
+			TimeoutSeconds: int(defaultEmbeddingsTimeout / time.Second),
+		},
 	}
 	configData, err := json.MarshalIndent(defaultConfig, "", "  ")
 	if err != nil {
@@ -156,38 +277,129 @@ func NewApp() *App {
 	}
 	client := resty.New()
 	client.SetTLSClientConfig(&tls.Config{InsecureSkipVerify: true})
+	provider, err := newLLMProviderChain(cfg, client)
+	if err != nil {
+		log.Fatalf("КРИТИЧЕСКАЯ ОШИБКА: не удалось инициализировать LLM-провайдер: %v", err)
+	}
+	log.Printf("Используется LLM-провайдер: %s", provider.Name())
+	synonyms, err := loadSynonyms()
+	if err != nil {
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: словарь синонимов не загружен: %v", err)
+	}
 	jsonRe := regexp.MustCompile(`(?s)({.*}|\[.*\])`)
 	return &App{
 		config:        cfg,
 		httpClient:    client,
+		llm:           provider,
 		jsonExtractor: jsonRe,
+		jobs:          make(map[string]*generationJob),
+		synonyms:      synonyms,
 	}
 }
 
-func init() {
-	unidocKey := "80052ea1203bf3421824c05723e5258fbacf3d126e6b5a8e49517dafcc81fee5"
-	err := license.SetMeteredKey(unidocKey)
-	if err != nil {
-		log.Fatalf("КРИТИЧЕСКАЯ ОШИБКА: не удалось активировать лицензию UniOffice: %v", err)
-	}
-	log.Println("Лицензия UniOffice успешно активирована.")
+var (
+	licenseOnce sync.Once
+	licenseErr  error
+)
+
+func activateLicense() error {
+	licenseOnce.Do(func() {
+		unidocKey := "80052ea1203bf3421824c05723e5258fbacf3d126e6b5a8e49517dafcc81fee5"
+		if err := license.SetMeteredKey(unidocKey); err != nil {
+			licenseErr = fmt.Errorf("не удалось активировать лицензию UniOffice: %w", err)
+			return
+		}
+		log.Println("Лицензия UniOffice успешно активирована.")
+	})
+	return licenseErr
 }
 
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+	a.eventSink = func(eventName string, data ...interface{}) {
+		runtime.EventsEmit(ctx, eventName, data...)
+	}
 	a.loadProductsFromCache()
 }
 
 func (a *App) GenerateAndCreateFiles(clientRequest string) (string, error) {
-	err := a.ensureDataIsLoaded()
+	jobID, err := a.StartGeneration(clientRequest, GenerationOptions{})
 	if err != nil {
 		return "", err
 	}
-	relevantProducts := a.retrieveRelevantProducts(clientRequest, 50)
+	return a.WaitGeneration(jobID)
+}
+
+const retrievalTopK = 50
+
+type proposal struct {
+	totals    FinalLogResponse
+	providers map[string]string
+}
+
+func (a *App) generateProposal(ctx context.Context, clientRequest string, options GenerationOptions) ([]byte, error) {
+	result, err := a.buildProposal(ctx, clientRequest, options)
+	if err != nil {
+		return nil, err
+	}
+	return a.renderProposal(ctx, clientRequest, options, result)
+}
+
+func (a *App) renderProposal(ctx context.Context, clientRequest string, options GenerationOptions, result proposal) ([]byte, error) {
+	totals := result.totals
+
+	reportProgress(ctx, ProgressPricing, 85, fmt.Sprintf("Позиций: %d, итого: %s, в т.ч. НДС %s", len(totals.FoundItems), totals.TotalCost, totals.TotalVAT), totals.FoundItems)
+	log.Println("Генерация DOCX файла с таблицей...")
+	reportProgress(ctx, ProgressRendering, 90, "Формирование DOCX документа", nil)
+	docx, err := a.renderProposalDocx(ctx, totals)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания DOCX файла: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	err = a.saveLogFile(clientRequest, options, result.providers, totals)
+	if err != nil {
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: не удалось сохранить лог-файл: %v", err)
+	}
+
+	log.Println("DOCX файл успешно создан.")
+	return docx, nil
+}
+
+func (a *App) buildProposal(ctx context.Context, clientRequest string, options GenerationOptions) (proposal, error) {
+	if err := a.config.Pricing.checkOptions(options); err != nil {
+		return proposal{}, err
+	}
+	reportProgress(ctx, ProgressLoading, 0, "Загрузка каталога товаров", nil)
+	err := a.ensureDataIsLoaded(ctx)
+	if err != nil {
+		return proposal{}, err
+	}
+	converter, err := a.newCurrencyConverter(options.Currency)
+	if err != nil {
+		return proposal{}, err
+	}
+	relevantProducts, keywordProvider := a.retrieveRelevantProducts(ctx, clientRequest, retrievalTopK)
+	if err := ctx.Err(); err != nil {
+		return proposal{}, err
+	}
+	result, err := a.assembleProposal(ctx, clientRequest, relevantProducts, converter, options)
+	if err != nil {
+		return proposal{}, err
+	}
+	if keywordProvider != "" {
+		result.providers["keywords"] = keywordProvider
+	}
+	return result, nil
+}
+
+func (a *App) assembleProposal(ctx context.Context, clientRequest string, relevantProducts []Product, converter *currencyConverter, options GenerationOptions) (proposal, error) {
 	if len(relevantProducts) == 0 {
-		return "", fmt.Errorf("не удалось найти ни одного релевантного товара для запроса: '%s'. Попробуйте переформулировать запрос", clientRequest)
+		return proposal{}, fmt.Errorf("не удалось найти ни одного релевантного товара для запроса: '%s'. Попробуйте переформулировать запрос", clientRequest)
 	}
-	productsJSON, _ := json.Marshal(relevantProducts)
+	productsJSON, _ := json.Marshal(promptProducts(relevantProducts))
 
 	planningPrompt := `Ты — главный инженер по комплектации заказов. Твоя репутация зависит от того, насколько полно и правильно ты соберешь заказ для клиента.
 
@@ -197,6 +409,10 @@ func (a *App) GenerateAndCreateFiles(clientRequest string) (string, error) {
 -   Если цель — **конкретная деталь** ("Крышка 200 мм"), твой список должен состоять **только из этой детали**.
 -   Если цель — **монтаж или сборка** ("комплект для монтажа короба 200х200"), твоя обязанность — включить в список **ВСЕ** необходимые для этого компоненты из предложенного каталога: сам короб, крышку, винты и гайки. Ты несешь ответственность за полноту комплекта.
 
+**ЕДИНИЦЫ И УПАКОВКА:**
+-   Количество указывай в единицах измерения товара (поле unit): метры для лотков и кабеля, штуки для метизов и т.д.
+-   Если у товара задана кратность упаковки (поле packSize), округляй количество вверх до кратного ей.
+
 **ПРАВИЛА ОФОРМЛЕНИЯ:**
 -   Твой ответ — **ТОЛЬКО маркированный список** в формате '- Название, Количество'.
 -   **ЗАПРЕЩЕНО:** Никаких заголовков, комментариев или пустых строк.
@@ -213,18 +429,28 @@ func (a *App) GenerateAndCreateFiles(clientRequest string) (string, error) {
 	fullPlanningPrompt := fmt.Sprintf(planningPrompt, string(productsJSON), clientRequest)
 
 	log.Println("Этап 1 (RAG): Запрос плана комплектации...")
-	engineeringPlan, err := a.callLLMForText(fullPlanningPrompt)
+	reportProgress(ctx, ProgressPlanning, 40, "Составление плана комплектации", nil)
+	planOptions := ChatOptions{Temperature: 0.1}
+	if a.config.LLM.StreamPlanning && hasProgressReporter(ctx) {
+		planOptions.OnStream = func(delta StreamDelta) {
+			reportStream(ctx, ProgressPlanning, delta)
+		}
+	}
+	planCompletion, err := a.callLLMForText(ctx, StagePlanning, fullPlanningPrompt, planOptions)
 	if err != nil {
-		return "", fmt.Errorf("ошибка на этапе 1 (планирование): %w", err)
+		return proposal{}, fmt.Errorf("ошибка на этапе 1 (планирование): %w", err)
 	}
+	engineeringPlan := planCompletion.Content
 	log.Printf("Получен план:\n---\n%s\n---", engineeringPlan)
+	reportProgress(ctx, ProgressPlanning, 60, "План комплектации получен", engineeringPlan)
 
 	finalJsonPrompt := `Ты — ассистент по обработке данных. Твоя задача — на основе **плана комплектации** и **JSON-списка РЕЛЕВАНТНЫХ товаров** сгенерировать итоговый JSON.
 
 **ПРАВИЛА:**
 1.  **СТРОГО СЛЕДУЙ ПЛАНУ.** Включай в ответ только те позиции, которые упомянуты в плане.
 2.  **ТОЧНОЕ СОПОСТАВЛЕНИЕ.** Найди в JSON-списке товары, которые максимально точно соответствуют описанию в плане.
-3.  **ТОЛЬКО JSON.** Твой ответ должен быть только валидным JSON-объектом без лишних символов и комментариев.
+3.  **ЕДИНИЦЫ.** Поле quantity — количество в единицах измерения товара (поле unit).
+4.  **ТОЛЬКО JSON.** Твой ответ должен быть только валидным JSON-объектом без лишних символов и комментариев.
 
 **Формат ответа:**
 ` + "```json" + `
@@ -245,187 +471,150 @@ func (a *App) GenerateAndCreateFiles(clientRequest string) (string, error) {
 	fullFinalJsonPrompt := fmt.Sprintf(finalJsonPrompt, engineeringPlan, string(productsJSON))
 
 	log.Println("Этап 2 (RAG): Запрос финального JSON...")
-	llmResponseJSON, err := a.callLLMForJSON(fullFinalJsonPrompt)
+	reportProgress(ctx, ProgressFormatting, 65, "Сопоставление плана с каталогом", nil)
+	jsonCompletion, err := a.callLLMForJSON(ctx, StageFormatting, fullFinalJsonPrompt, ChatOptions{Temperature: 0.1, JSONMode: true})
 	if err != nil {
-		return "", fmt.Errorf("ошибка на этапе 2 (форматирование JSON): %w", err)
+		return proposal{}, fmt.Errorf("ошибка на этапе 2 (форматирование JSON): %w", err)
+	}
+	llmResponseJSON := jsonCompletion.Content
+	providers := map[string]string{
+		"planning":   planCompletion.Provider,
+		"formatting": jsonCompletion.Provider,
 	}
 
 	var llmResponse LLMResponse
 	if err := json.Unmarshal([]byte(llmResponseJSON), &llmResponse); err != nil {
-		return "", fmt.Errorf("LLM вернула невалидный JSON: %w. Ответ: %s", err, llmResponseJSON)
+		return proposal{}, fmt.Errorf("LLM вернула невалидный JSON: %w. Ответ: %s", err, llmResponseJSON)
 	}
 
+	reportProgress(ctx, ProgressPricing, 80, "Расчет стоимости", nil)
+	catalog := a.currentCatalog()
 	var finalItems []TCPItem
-	var totalCost int
 	for _, item := range llmResponse.FoundItems {
-		product, exists := a.productMap[item.ID]
+		product, exists := catalog.productMap[item.ID]
 		if !exists {
 			log.Printf("ПРЕДУПРЕЖДЕНИЕ: LLM вернула несуществующий ID: %d. Позиция пропущена.", item.ID)
 			continue
 		}
-		subtotal := product.Price * item.Quantity
-		finalItems = append(finalItems, TCPItem{
-			Name:     product.Name,
-			Quantity: item.Quantity,
-			Price:    product.Price,
-			Subtotal: subtotal,
-		})
-		totalCost += subtotal
+		if item.Quantity <= 0 {
+			log.Printf("ПРЕДУПРЕЖДЕНИЕ: LLM вернула некорректное количество %d для '%s' (ID %d). Позиция пропущена.", item.Quantity, product.Name, item.ID)
+			continue
+		}
+		quantity := roundToPackSize(item.Quantity, product.PackSize)
+		if quantity != item.Quantity {
+			log.Printf("Количество '%s' округлено до кратности упаковки %s: %d -> %d.", product.Name, formatQuantity(product.PackSize), item.Quantity, quantity)
+		}
+		if product.Stock != nil && float64(quantity) > *product.Stock {
+			log.Printf("ПРЕДУПРЕЖДЕНИЕ: остатка '%s' недостаточно: нужно %d, на складе %s.", product.Name, quantity, formatQuantity(*product.Stock))
+		}
+		pricing := a.config.Pricing.priceFor(product, quantity, options)
+		if pricing.rule != "" {
+			log.Printf("Цена '%s': %s -> %s (%s).", product.Name, product.Price, pricing.price, pricing.rule)
+		}
+		basePrice, err := converter.convert(product.Price)
+		if err != nil {
+			return proposal{}, fmt.Errorf("ошибка пересчета цены '%s': %w", product.Name, err)
+		}
+		price, err := converter.convert(pricing.price)
+		if err != nil {
+			return proposal{}, fmt.Errorf("ошибка пересчета цены '%s': %w", product.Name, err)
+		}
+		tcpItem := TCPItem{
+			ProductID:  product.ID,
+			ProductKey: product.Key,
+			Article:    product.Article,
+			Name:       product.Name,
+			Unit:       product.Unit,
+			Category:   product.Category,
+			Quantity:   quantity,
+			BasePrice:  basePrice,
+			Discount:   pricing.discount,
+			Price:      price,
+			Rule:       pricing.rule,
+			Stock:      product.Stock,
+			Subtotal:   price.Mul(quantity),
+		}
+		a.config.VAT.applyTo(&tcpItem, a.config.VAT.rateFor(product))
+		finalItems = append(finalItems, tcpItem)
 	}
-
-	err = a.saveLogFile(clientRequest, finalItems, totalCost)
+	totals, err := calculateTotals(finalItems, a.config.VAT.pricesIncludeVAT(), converter.target)
 	if err != nil {
-		log.Printf("ПРЕДУПРЕЖДЕНИЕ: не удалось сохранить лог-файл: %v", err)
+		return proposal{}, err
 	}
-
-	log.Println("Генерация DOCX файла с таблицей...")
-	base64Docx, err := a.createStyledDocxFile(finalItems, totalCost)
-	if err != nil {
-		return "", fmt.Errorf("ошибка создания DOCX файла: %w", err)
+	totals.ExchangeRates = converter.appliedRates()
+	for _, rate := range totals.ExchangeRates {
+		log.Printf("Пересчет в %s: %s", converter.target, rate)
 	}
-
-	log.Println("DOCX файл успешно создан и отправлен на фронтенд.")
-	return base64Docx, nil
+	return proposal{totals: totals, providers: providers}, nil
 }
 
-func (a *App) callLLMForJSON(prompt string) (string, error) {
-	rawContent, err := a.callLLMForText(prompt)
+func (a *App) callLLMForJSON(ctx context.Context, stage LLMStage, prompt string, opts ChatOptions) (ChatCompletion, error) {
+	completion, err := a.callLLMForText(ctx, stage, prompt, opts)
 	if err != nil {
-		return "", err
+		return ChatCompletion{}, err
 	}
-	jsonMatch := a.jsonExtractor.FindString(rawContent)
+	jsonMatch := a.jsonExtractor.FindString(completion.Content)
 	if jsonMatch == "" {
-		return "", fmt.Errorf("не удалось найти JSON в ответе LLM. Ответ был: %s", rawContent)
+		return ChatCompletion{}, fmt.Errorf("не удалось найти JSON в ответе LLM (%s). Ответ был: %s", completion.Provider, completion.Content)
 	}
-	return jsonMatch, nil
+	completion.Content = jsonMatch
+	return completion, nil
 }
 
-func (a *App) callLLMForText(prompt string) (string, error) {
-	if a.config.UseGigaChat {
-		log.Println("Используется API: GigaChat")
-		return a.callGigaChatAPIInternal(prompt)
+func (a *App) callLLMForText(ctx context.Context, stage LLMStage, prompt string, opts ChatOptions) (ChatCompletion, error) {
+	if opts.Timeout == 0 {
+		opts.Timeout = a.config.LLM.Timeouts.forStage(stage)
 	}
-	log.Println("Используется API: Ollama")
-	return a.callOllamaAPIInternal(prompt)
+	return a.llm.Chat(ctx, []ChatMessage{{Role: "user", Content: prompt}}, opts)
 }
 
-func (a *App) callGigaChatAPIInternal(prompt string) (string, error) {
-	token, err := a.getAccessToken()
-	if err != nil {
-		return "", err
-	}
-	requestBody := GigaChatRequest{
-		Model:       a.config.GigaChat.Model,
-		Messages:    []GigaChatMessage{{Role: "user", Content: prompt}},
-		Temperature: 0.1,
-	}
-	resp, err := a.httpClient.R().
-		SetHeader("Content-Type", "application/json").
-		SetHeader("Accept", "application/json").
-		SetAuthToken(token).
-		SetBody(requestBody).
-		Post("https://gigachat.devices.sberbank.ru/api/v1/chat/completions")
-
-	if err != nil {
-		return "", fmt.Errorf("сетевая ошибка при вызове GigaChat: %w", err)
-	}
-	log.Printf("RAW RESPONSE BODY FROM GIGACHAT:\n%s\n", resp.String())
-	if resp.IsError() {
-		return "", fmt.Errorf("ошибка от API GigaChat: %s - %s", resp.Status(), resp.String())
-	}
-	var gigaResponse GigaChatResponse
-	if err := json.Unmarshal(resp.Body(), &gigaResponse); err != nil {
-		return "", fmt.Errorf("ошибка парсинга ответа GigaChat: %w", err)
+func (a *App) baseContext() context.Context {
+	if a.ctx != nil {
+		return a.ctx
 	}
-	if len(gigaResponse.Choices) == 0 {
-		return "", fmt.Errorf("GigaChat вернул пустой ответ")
-	}
-	return gigaResponse.Choices[0].Message.Content, nil
+	return context.Background()
 }
 
-func (a *App) callOllamaAPIInternal(prompt string) (string, error) {
-	requestBody := OllamaRequest{
-		Model:    a.config.Ollama.Model,
-		Messages: []GigaChatMessage{{Role: "user", Content: prompt}},
-		Stream:   false,
-	}
-	apiURL := a.config.Ollama.BaseURL + "/api/chat"
-	resp, err := a.httpClient.R().
-		SetHeader("Content-Type", "application/json").
-		SetBody(requestBody).
-		Post(apiURL)
-
-	if err != nil {
-		return "", fmt.Errorf("сетевая ошибка при вызове Ollama: %w", err)
+func (a *App) retrieveRelevantProducts(ctx context.Context, query string, topK int) ([]Product, string) {
+	reportProgress(ctx, ProgressKeywords, 5, "Извлечение ключевых слов", nil)
+	keywords, keywordProvider, err := a.extractKeywordsWithLLM(ctx, query)
+	if ctx.Err() != nil {
+		return []Product{}, ""
 	}
-	log.Printf("RAW RESPONSE BODY FROM OLLAMA:\n%s\n", resp.String())
-	if resp.IsError() {
-		return "", fmt.Errorf("ошибка от API Ollama: %s - %s", resp.Status(), resp.String())
-	}
-	var ollamaResponse OllamaResponse
-	if err := json.Unmarshal(resp.Body(), &ollamaResponse); err != nil {
-		return "", fmt.Errorf("ошибка парсинга ответа Ollama: %w", err)
-	}
-	return ollamaResponse.Message.Content, nil
-}
-
-func (a *App) retrieveRelevantProducts(query string, topK int) []Product {
-	keywords, err := a.extractKeywordsWithLLM(query)
 	if err != nil {
 		log.Printf("ПРЕДУПРЕЖДЕНИЕ: Не удалось извлечь ключевые слова через LLM, переключаюсь на простой поиск. Ошибка: %v", err)
 		keywords = tokenize(query)
 	}
 
-	if len(keywords) == 0 {
-		return []Product{}
-	}
-
-	type ScoredProduct struct {
-		Product Product
-		Score   int
-	}
-	var scoredProducts []ScoredProduct
-
-	for _, product := range a.products {
-		score := 0
-		productNameLower := strings.ToLower(product.Name)
-
-		for _, keyword := range keywords {
-			if strings.Contains(productNameLower, keyword) {
-				score++
-			}
-		}
+	reportProgress(ctx, ProgressKeywords, 20, fmt.Sprintf("Ключевые слова: %s", strings.Join(keywords, ", ")), keywords)
 
-		if score > 0 {
-			scoredProducts = append(scoredProducts, ScoredProduct{Product: product, Score: score})
-		}
+	if expansions := a.synonymDictionary().expand(append([]string{query}, keywords...)); len(expansions) > 0 {
+		log.Printf("Запрос расширен по словарю синонимов: %s", strings.Join(expansions, ", "))
+		keywords = append(keywords, expansions...)
 	}
 
-	sort.Slice(scoredProducts, func(i, j int) bool {
-		if scoredProducts[i].Score != scoredProducts[j].Score {
-			return scoredProducts[i].Score > scoredProducts[j].Score
-		}
-		return len(scoredProducts[i].Product.Name) < len(scoredProducts[j].Product.Name)
-	})
-
-	var relevantProducts []Product
-	limit := topK
-	if len(scoredProducts) < topK {
-		limit = len(scoredProducts)
+	scoredProducts := a.currentCatalog().index.search(queryTerms(keywords), topK*2)
+	if semantic := a.semanticSearch(ctx, query, topK*2); len(semantic) > 0 {
+		log.Printf("Гибридный поиск: %d кандидатов по смысловому сходству объединены с %d кандидатами по ключевым словам.", len(semantic), len(scoredProducts))
+		scoredProducts = hybridRank(scoredProducts, semantic, a.config.Embeddings.weight(), topK)
+	} else if len(scoredProducts) > topK {
+		scoredProducts = scoredProducts[:topK]
 	}
-	for i := 0; i < limit; i++ {
-		relevantProducts = append(relevantProducts, scoredProducts[i].Product)
+	relevantProducts := make([]Product, 0, len(scoredProducts))
+	for _, scored := range scoredProducts {
+		relevantProducts = append(relevantProducts, scored.Product)
 	}
 
-	log.Printf("RAG Этап 2: Найдено %d товаров по ключевым словам от LLM. Передаю для финальной сборки.", len(relevantProducts))
-	return relevantProducts
+	log.Printf("RAG Этап 2: Найдено %d товаров по ключевым словам и смысловому сходству. Передаю для финальной сборки.", len(relevantProducts))
+	reportProgress(ctx, ProgressRetrieval, 30, fmt.Sprintf("Найдено релевантных товаров: %d", len(relevantProducts)), relevantProducts)
+	return relevantProducts, keywordProvider
 }
 
 type LLMKeywordResponse struct {
 	Keywords []string `json:"keywords"`
 }
 
-func (a *App) extractKeywordsWithLLM(query string) ([]string, error) {
+func (a *App) extractKeywordsWithLLM(ctx context.Context, query string) ([]string, string, error) {
 	prompt := `Твоя задача — проанализировать запрос клиента для поиска товаров на складе и извлечь из него только самые важные, уникальные ключевые слова.
 
 ПРАВИЛА:
@@ -443,28 +632,29 @@ func (a *App) extractKeywordsWithLLM(query string) ([]string, error) {
 }
 ` + "```" + `
 
----
+%s---
 ЗАПРОС КЛИЕНТА ДЛЯ ОБРАБОТКИ:
 "%s"
 ---
 ТВОЙ JSON-ОТВЕТ:
 `
-	fullPrompt := fmt.Sprintf(prompt, query)
+	fullPrompt := fmt.Sprintf(prompt, a.synonymDictionary().promptSection(), query)
 
 	log.Println("RAG Этап 1: Извлечение ключевых слов через LLM...")
 
-	jsonResponse, err := a.callLLMForJSON(fullPrompt)
+	keywordCompletion, err := a.callLLMForJSON(ctx, StageKeywords, fullPrompt, ChatOptions{Temperature: 0.1, JSONMode: true})
 	if err != nil {
-		return nil, fmt.Errorf("LLM не смогла извлечь ключевые слова: %w", err)
+		return nil, "", fmt.Errorf("LLM не смогла извлечь ключевые слова: %w", err)
 	}
 
+	jsonResponse := keywordCompletion.Content
 	var keywordResponse LLMKeywordResponse
 	if err := json.Unmarshal([]byte(jsonResponse), &keywordResponse); err != nil {
-		return nil, fmt.Errorf("не удалось распарсить JSON с ключевыми словами: %w. Ответ был: %s", err, jsonResponse)
+		return nil, "", fmt.Errorf("не удалось распарсить JSON с ключевыми словами: %w. Ответ был: %s", err, jsonResponse)
 	}
 
-	log.Printf("LLM извлекла ключевые слова: %v", keywordResponse.Keywords)
-	return keywordResponse.Keywords, nil
+	log.Printf("LLM (%s) извлекла ключевые слова: %v", keywordCompletion.Provider, keywordResponse.Keywords)
+	return keywordResponse.Keywords, keywordCompletion.Provider, nil
 }
 
 func tokenize(text string) []string {
@@ -474,50 +664,57 @@ func tokenize(text string) []string {
 	return strings.Fields(textWithSpaces)
 }
 
-func (a *App) getAccessToken() (string, error) {
-	a.tokenMutex.Lock()
-	defer a.tokenMutex.Unlock()
-	if a.gigaToken != "" && time.Now().Before(a.tokenExpiresAt) {
-		return a.gigaToken, nil
-	}
-	log.Println("Токен GigaChat истек или отсутствует. Получение нового токена...")
-	resp, err := a.httpClient.R().
-		SetHeader("Content-Type", "application/x-www-form-urlencoded").
-		SetHeader("Accept", "application/json").
-		SetHeader("RqUID", "a1a2a3a4-a5a6-a7a8-a9a0-a1a2a3a4a5a6").
-		SetHeader("Authorization", "Basic "+a.config.GigaChat.APIKey).
-		SetFormData(map[string]string{
-			"scope": "GIGACHAT_API_PERS",
-		}).
-		Post("https://ngw.devices.sberbank.ru:9443/api/v2/oauth")
-	if err != nil {
-		return "", fmt.Errorf("ошибка при запросе токена: %w", err)
-	}
-	if resp.IsError() {
-		return "", fmt.Errorf("ошибка от API при получении токена: %s - %s", resp.Status(), resp.String())
-	}
-	var tokenResponse struct {
-		AccessToken string `json:"access_token"`
-		ExpiresAt   int64  `json:"expires_at"`
+type promptProduct struct {
+	ID       int      `json:"id"`
+	Article  string   `json:"article,omitempty"`
+	Name     string   `json:"name"`
+	Unit     string   `json:"unit,omitempty"`
+	Category string   `json:"category,omitempty"`
+	Price    float64  `json:"price"`
+	Stock    *float64 `json:"stock,omitempty"`
+	PackSize float64  `json:"packSize,omitempty"`
+}
+
+func promptProducts(products []Product) []promptProduct {
+	result := make([]promptProduct, len(products))
+	for i, p := range products {
+		result[i] = promptProduct{
+			ID:       p.ID,
+			Article:  p.Article,
+			Name:     p.Name,
+			Unit:     p.Unit,
+			Category: p.Category,
+			Price:    p.Price.Float(),
+			Stock:    p.Stock,
+			PackSize: p.PackSize,
+		}
 	}
-	if err := json.Unmarshal(resp.Body(), &tokenResponse); err != nil {
-		return "", fmt.Errorf("ошибка парсинга ответа с токеном: %w", err)
+	return result
+}
+
+func roundToPackSize(quantity int, packSize float64) int {
+	pack := int(packSize)
+	if pack <= 1 || float64(pack) != packSize || quantity%pack == 0 {
+		return quantity
 	}
-	a.gigaToken = tokenResponse.AccessToken
-	a.tokenExpiresAt = time.Unix(tokenResponse.ExpiresAt/1000, 0).Add(-1 * time.Minute)
-	log.Println("Новый токен GigaChat успешно получен.")
-	return a.gigaToken, nil
+	return (quantity/pack + 1) * pack
 }
 
-func (a *App) createStyledDocxFile(items []TCPItem, totalCost int) (string, error) {
+func (a *App) renderProposalDocx(ctx context.Context, totals FinalLogResponse) ([]byte, error) {
+	if err := activateLicense(); err != nil {
+		return nil, err
+	}
 	doc, err := document.Open("template.docx")
 	if err != nil {
-		return "", fmt.Errorf("ошибка открытия template.docx: %w", err)
+		return nil, fmt.Errorf("ошибка открытия template.docx: %w", err)
 	}
 	orderID := fmt.Sprintf("%d-%d", time.Now().Unix(), rand.Intn(1000))
 	replaceAllText(doc, "{document_title}", "Технико-коммерческое предложение")
 	replaceAllText(doc, "{order_id}", orderID)
-	replaceAllText(doc, "{total_price}", fmt.Sprintf("%d руб.", totalCost))
+	replaceAllText(doc, "{total_price}", totals.TotalCost.String())
+	replaceAllText(doc, "{total_price_words}", amountInWords(totals.TotalCost))
+	replaceAllText(doc, "{total_vat}", totals.TotalVAT.String())
+	replaceAllText(doc, "{exchange_rate}", exchangeRatesText(totals.ExchangeRates))
 	var tablePara document.Paragraph
 	for _, p := range doc.Paragraphs() {
 		var fullParaText strings.Builder
@@ -530,7 +727,7 @@ func (a *App) createStyledDocxFile(items []TCPItem, totalCost int) (string, erro
 		}
 	}
 	if (tablePara == document.Paragraph{}) {
-		return "", fmt.Errorf("плейсхолдер {components_table} не найден в template.docx")
+		return nil, fmt.Errorf("плейсхолдер {components_table} не найден в template.docx")
 	}
 	for _, run := range tablePara.Runs() {
 		tablePara.RemoveRun(run)
@@ -541,8 +738,8 @@ func (a *App) createStyledDocxFile(items []TCPItem, totalCost int) (string, erro
 	borders := tblProps.Borders()
 	borders.SetAll(wml.ST_BorderSingle, color.Auto, measurement.Point)
 	headerRow := table.AddRow()
-	headers := []string{"Наименование", "Кол-во", "Цена за шт.", "Сумма"}
-	for _, h := range headers {
+	columns := tcpTableColumns(totals)
+	for _, column := range columns {
 		cell := headerRow.AddCell()
 		cell.Properties().SetVerticalAlignment(wml.ST_VerticalJcCenter)
 		p := cell.AddParagraph()
@@ -550,33 +747,37 @@ func (a *App) createStyledDocxFile(items []TCPItem, totalCost int) (string, erro
 		run := p.AddRun()
 		run.Properties().SetBold(true)
 		run.Properties().SetColor(color.Black)
-		run.AddText(h)
+		run.AddText(column.title)
 	}
-	for _, item := range items {
+	for _, item := range totals.FoundItems {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		row := table.AddRow()
-		row.AddCell().AddParagraph().AddRun().AddText(item.Name)
-		row.AddCell().AddParagraph().AddRun().AddText(strconv.Itoa(item.Quantity))
-		row.AddCell().AddParagraph().AddRun().AddText(fmt.Sprintf("%d руб.", item.Price))
-		row.AddCell().AddParagraph().AddRun().AddText(fmt.Sprintf("%d руб.", item.Subtotal))
-	}
-	totalRow := table.AddRow()
-	totalLabelCell := totalRow.AddCell()
-	totalLabelCell.Properties().SetColumnSpan(3)
-	totalLabelPara := totalLabelCell.AddParagraph()
-	totalLabelPara.Properties().SetAlignment(wml.ST_JcRight)
-	totalLabelRun := totalLabelPara.AddRun()
-	totalLabelRun.Properties().SetBold(true)
-	totalLabelRun.AddText("Итого:")
-	totalValueCell := totalRow.AddCell()
-	totalValuePara := totalValueCell.AddParagraph()
-	totalValueRun := totalValuePara.AddRun()
-	totalValueRun.Properties().SetBold(true)
-	totalValueRun.AddText(fmt.Sprintf("%d руб.", totalCost))
+		for _, column := range columns {
+			row.AddCell().AddParagraph().AddRun().AddText(column.value(item))
+		}
+	}
+	for _, summary := range tcpSummaryRows(totals) {
+		totalRow := table.AddRow()
+		totalLabelCell := totalRow.AddCell()
+		totalLabelCell.Properties().SetColumnSpan(len(columns) - 1)
+		totalLabelPara := totalLabelCell.AddParagraph()
+		totalLabelPara.Properties().SetAlignment(wml.ST_JcRight)
+		totalLabelRun := totalLabelPara.AddRun()
+		totalLabelRun.Properties().SetBold(true)
+		totalLabelRun.AddText(summary.label)
+		totalValueCell := totalRow.AddCell()
+		totalValuePara := totalValueCell.AddParagraph()
+		totalValueRun := totalValuePara.AddRun()
+		totalValueRun.Properties().SetBold(true)
+		totalValueRun.AddText(summary.value)
+	}
 	var buf bytes.Buffer
 	if err := doc.Save(&buf); err != nil {
-		return "", fmt.Errorf("ошибка сохранения docx в буфер: %w", err)
+		return nil, fmt.Errorf("ошибка сохранения docx в буфер: %w", err)
 	}
-	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+	return buf.Bytes(), nil
 }
 
 func replaceAllText(doc *document.Document, old, new string) {
@@ -618,87 +819,89 @@ func (a *App) loadProductsFromCache() {
 	if err == nil {
 		var products []Product
 		if json.Unmarshal(cachedData, &products) == nil {
-			a.products = products
-			a.buildProductMap()
+			products = ensureProductKeys(products)
+			a.setCatalog(products)
 			log.Println("Успешно загружены данные о продуктах из кэша 'products.json'.")
+			a.refreshEmbeddingsAsync(products, catalogHash(cachedData))
 			return
 		}
 	}
-	log.Println("Файл 'products.json' не найден или поврежден. Данные будут загружены из 'materials.csv' при первом запросе.")
+	log.Printf("Файл 'products.json' не найден или поврежден. Данные будут загружены из '%s' при первом запросе.", a.config.Catalog.sourcePath())
 }
 
-func (a *App) buildProductMap() {
-	a.productMap = make(map[int]Product)
-	for _, p := range a.products {
-		a.productMap[p.ID] = p
-	}
+type catalogSnapshot struct {
+	products   []Product
+	productMap map[int]Product
+	keyMap     map[string]int
+	index      *productIndex
 }
 
-func (a *App) ensureDataIsLoaded() error {
-	a.dataLoadMutex.Lock()
-	defer a.dataLoadMutex.Unlock()
-	if len(a.products) > 0 {
-		return nil
+func newCatalogSnapshot(products []Product) *catalogSnapshot {
+	productMap := make(map[int]Product, len(products))
+	keyMap := make(map[string]int, len(products))
+	for _, p := range products {
+		productMap[p.ID] = p
+		if p.Key != "" {
+			keyMap[p.Key] = p.ID
+		}
 	}
-	log.Println("Данные не загружены, запускаю процесс парсинга 'materials.csv'...")
-	rawData, err := os.ReadFile("materials.csv")
-	if err != nil {
-		return fmt.Errorf("не удалось прочитать 'materials.csv': %w", err)
+	return &catalogSnapshot{products: products, productMap: productMap, keyMap: keyMap, index: buildProductIndex(products)}
+}
+
+func (c *catalogSnapshot) productByKey(key string) (Product, bool) {
+	id, ok := c.keyMap[key]
+	if !ok {
+		return Product{}, false
 	}
-	prompt := `Ты — сверхточный ассистент по извлечению данных. Твоя задача — преобразовать предоставленный неупорядоченный текст в строгий JSON-массив. Каждая строка текста - отдельный товар. Каждый элемент массива должен быть объектом с полями "name" (строка) и "price" (число).
+	p, ok := c.productMap[id]
+	return p, ok
+}
 
-Правила:
-- Извлекай цену как число, убирая "руб." и другие символы.
-- Название товара — это всё, что находится до цены.
-- Если в строке нет цены, игнорируй её.
-- Не добавляй никаких комментариев или текста до и после JSON. Вывод должен быть только валидным JSON-массивом.
+func (a *App) setCatalog(products []Product) {
+	catalog := newCatalogSnapshot(products)
+	a.catalogMutex.Lock()
+	a.catalog = catalog
+	a.catalogMutex.Unlock()
+}
 
-Вот текст для обработки:
----
-%s
----`
-	fullPrompt := fmt.Sprintf(prompt, string(rawData))
-	parsedJSON, err := a.callLLMForJSON(fullPrompt)
-	if err != nil {
-		return fmt.Errorf("ошибка парсинга файла через LLM: %w", err)
-	}
-	var parsedProducts []ParsedProduct
-	if err := json.Unmarshal([]byte(parsedJSON), &parsedProducts); err != nil {
-		return fmt.Errorf("LLM вернула невалидный JSON после парсинга: %w. Ответ: %s", err, parsedJSON)
-	}
-	finalProducts := make([]Product, len(parsedProducts))
-	for i, p := range parsedProducts {
-		finalProducts[i] = Product{ID: i + 1, Name: p.Name, Price: p.Price}
-	}
-	finalJSONData, err := json.MarshalIndent(finalProducts, "", "  ")
-	if err != nil {
-		return fmt.Errorf("ошибка финальной сериализации: %w", err)
-	}
-	err = os.WriteFile("products.json", finalJSONData, 0644)
-	if err != nil {
-		return fmt.Errorf("не удалось сохранить кэш в 'products.json': %w", err)
+func (a *App) currentCatalog() *catalogSnapshot {
+	a.catalogMutex.RLock()
+	defer a.catalogMutex.RUnlock()
+	if a.catalog == nil {
+		return newCatalogSnapshot(nil)
 	}
-	log.Println("Данные успешно распарсены и сохранены в 'products.json'.")
-	a.loadProductsFromCache()
-	if len(a.products) == 0 {
-		return fmt.Errorf("не удалось загрузить данные в память даже после парсинга")
+	return a.catalog
+}
+
+func (a *App) ensureDataIsLoaded(ctx context.Context) error {
+	a.dataLoadMutex.Lock()
+	defer a.dataLoadMutex.Unlock()
+	source := a.config.Catalog.sourcePath()
+	if len(a.currentCatalog().products) > 0 {
+		if !a.catalogSourceChanged() {
+			return nil
+		}
+		log.Printf("Файл '%s' изменился после сохранения кэша, запускаю повторный импорт...", source)
+	} else {
+		log.Printf("Данные не загружены, запускаю импорт каталога '%s'...", source)
 	}
-	return nil
+	_, err := a.reimportCatalogLocked(ctx)
+	return err
 }
 
-func (a *App) saveLogFile(query string, items []TCPItem, totalCost int) error {
+func (a *App) saveLogFile(query string, options GenerationOptions, providers map[string]string, totals FinalLogResponse) error {
 	logEntry := LogEntry{
-		Query: query,
-		Response: FinalLogResponse{
-			FoundItems: items,
-			TotalCost:  totalCost,
-		},
+		Query:     query,
+		Customer:  options.Customer,
+		Overrides: options.Overrides,
+		Providers: providers,
+		Response:  totals,
 	}
 	logData, err := json.MarshalIndent(logEntry, "", "  ")
 	if err != nil {
 		return fmt.Errorf("не удалось сформировать JSON для лога: %w", err)
 	}
-	filename := fmt.Sprintf("log_%d.json", time.Now().Unix())
+	filename := fmt.Sprintf("log_%d.json", time.Now().UnixNano())
 	err = os.WriteFile(filename, logData, 0644)
 	if err != nil {
 		return fmt.Errorf("не удалось записать лог-файл на диск: %w", err)
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+type fakeLLMProvider struct {
+	name    string
+	replies []string
+	err     error
+	prompts []string
+}
+
+func (f *fakeLLMProvider) Name() string {
+	return f.name
+}
+
+func (f *fakeLLMProvider) Chat(ctx context.Context, messages []ChatMessage, opts ChatOptions) (ChatCompletion, error) {
+	f.prompts = append(f.prompts, messages[len(messages)-1].Content)
+	if f.err != nil {
+		return ChatCompletion{}, f.err
+	}
+	if len(f.replies) == 0 {
+		return ChatCompletion{}, errors.New("неожиданный вызов LLM")
+	}
+	reply := f.replies[0]
+	f.replies = f.replies[1:]
+	return ChatCompletion{Content: reply, Provider: f.name}, nil
+}
+
+func newTestApp(products []Product, llm LLMProvider) *App {
+	app := &App{
+		config:        Config{},
+		llm:           llm,
+		jsonExtractor: regexp.MustCompile(`(?s)({.*}|\[.*\])`),
+		jobs:          make(map[string]*generationJob),
+	}
+	app.setCatalog(products)
+	return app
+}
+
+func testCatalog() []Product {
+	return []Product{
+		{ID: 1, Name: "Лоток перфорированный 100х100", Article: "LP-100", Unit: "м", Price: Money{Kopecks: 45050, Currency: "RUB"}},
+		{ID: 2, Name: "Гайка М10", Article: "G-M10", Unit: "шт", Price: Money{Kopecks: 320, Currency: "RUB"}, PackSize: 100},
+		{ID: 3, Name: "Крышка для лотка 100", Article: "KR-100", Unit: "м", Price: Money{Kopecks: 21000, Currency: "RUB"}},
+	}
+}
+
+func TestAssembleProposal(t *testing.T) {
+	llm := &fakeLLMProvider{name: "fake", replies: []string{
+		"- Лоток перфорированный 100х100, 12\n- Гайка М10, 150",
+		"Вот ответ:\n```json\n{\"found_items\": [{\"id\": 1, \"quantity\": 12}, {\"id\": 2, \"quantity\": 150}, {\"id\": 99, \"quantity\": 1}, {\"id\": 3, \"quantity\": -3}, {\"id\": 3, \"quantity\": 0}]}\n```",
+	}}
+	app := newTestApp(testCatalog(), llm)
+	converter, err := app.newCurrencyConverter("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := app.assembleProposal(context.Background(), "лоток 100х100 12 метров с гайками", testCatalog(), converter, GenerationOptions{})
+	if err != nil {
+		t.Fatalf("assembleProposal: %v", err)
+	}
+	if len(llm.prompts) != 2 {
+		t.Fatalf("ожидалось 2 вызова LLM, получено %d", len(llm.prompts))
+	}
+	if !strings.Contains(llm.prompts[0], "лоток 100х100 12 метров с гайками") || !strings.Contains(llm.prompts[0], "LP-100") {
+		t.Errorf("в промпт планирования не попали запрос или каталог:\n%s", llm.prompts[0])
+	}
+	if !strings.Contains(llm.prompts[1], "Гайка М10, 150") {
+		t.Errorf("в промпт форматирования не попал план:\n%s", llm.prompts[1])
+	}
+
+	items := result.totals.FoundItems
+	if len(items) != 2 {
+		t.Fatalf("ожидалось 2 позиции (несуществующий ID и неположительные количества пропущены), получено %d: %+v", len(items), items)
+	}
+	want := []struct {
+		id       int
+		quantity int
+		subtotal int64
+		vat      int64
+	}{
+		{id: 1, quantity: 12, subtotal: 540600, vat: 90100},
+		{id: 2, quantity: 200, subtotal: 64000, vat: 10667},
+	}
+	for i, w := range want {
+		item := items[i]
+		if item.ProductID != w.id || item.Quantity != w.quantity || item.Subtotal.Kopecks != w.subtotal || item.VATAmount.Kopecks != w.vat || item.VATRate != defaultVATRate {
+			t.Errorf("позиция %d = {id %d, qty %d, subtotal %d, vat %d, rate %d}, ожидалось %+v", i, item.ProductID, item.Quantity, item.Subtotal.Kopecks, item.VATAmount.Kopecks, item.VATRate, w)
+		}
+	}
+	if result.totals.TotalCost.Kopecks != 604600 || result.totals.TotalVAT.Kopecks != 100767 || result.totals.TotalWithoutVAT.Kopecks != 503833 {
+		t.Errorf("итоги = %s / НДС %s / без НДС %s", result.totals.TotalCost, result.totals.TotalVAT, result.totals.TotalWithoutVAT)
+	}
+	if result.providers["planning"] != "fake" || result.providers["formatting"] != "fake" {
+		t.Errorf("провайдеры = %v", result.providers)
+	}
+}
+
+func TestAssembleProposalErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		products []Product
+		llm      *fakeLLMProvider
+		wantErr  string
+	}{
+		{
+			name:     "нет релевантных товаров",
+			products: nil,
+			llm:      &fakeLLMProvider{name: "fake"},
+			wantErr:  "не удалось найти ни одного релевантного товара",
+		},
+		{
+			name:     "ошибка планирования",
+			products: testCatalog(),
+			llm:      &fakeLLMProvider{name: "fake", err: errors.New("нет сети")},
+			wantErr:  "этапе 1",
+		},
+		{
+			name:     "ответ без JSON",
+			products: testCatalog(),
+			llm:      &fakeLLMProvider{name: "fake", replies: []string{"- Гайка М10, 100", "не знаю"}},
+			wantErr:  "не удалось найти JSON",
+		},
+		{
+			name:     "невалидный JSON",
+			products: testCatalog(),
+			llm:      &fakeLLMProvider{name: "fake", replies: []string{"- Гайка М10, 100", `{"found_items": [{"id": "два"}]}`}},
+			wantErr:  "невалидный JSON",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(testCatalog(), tt.llm)
+			converter, err := app.newCurrencyConverter("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = app.assembleProposal(context.Background(), "гайки", tt.products, converter, GenerationOptions{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ошибка = %v, ожидалось содержание %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetrieveRelevantProducts(t *testing.T) {
+	llm := &fakeLLMProvider{name: "fake", replies: []string{`{"keywords": ["крышка", "лоток"]}`}}
+	app := newTestApp(testCatalog(), llm)
+
+	products, provider := app.retrieveRelevantProducts(context.Background(), "крышка на лоток", 2)
+	if provider != "fake" {
+		t.Errorf("провайдер ключевых слов = %q", provider)
+	}
+	if len(products) == 0 || products[0].ID != 3 {
+		t.Fatalf("первым должен быть найден товар 3, получено %+v", products)
+	}
+
+	app.llm = &fakeLLMProvider{name: "fake", err: errors.New("нет сети")}
+	products, provider = app.retrieveRelevantProducts(context.Background(), "гайка м10", 2)
+	if provider != "" {
+		t.Errorf("при откате на простой поиск провайдер должен быть пустым, получено %q", provider)
+	}
+	if len(products) == 0 || products[0].ID != 2 {
+		t.Fatalf("первым должен быть найден товар 2, получено %+v", products)
+	}
+}
+
+func TestCatalogSwapDuringReads(t *testing.T) {
+	app := newTestApp(testCatalog(), &fakeLLMProvider{name: "fake", err: errors.New("нет сети")})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			app.setCatalog(testCatalog())
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		catalog := app.currentCatalog()
+		if _, ok := catalog.productMap[2]; !ok || len(catalog.index.search([]string{"гайк"}, 1)) != 1 {
+			t.Fatalf("снимок каталога неполон: %d товаров", len(catalog.productMap))
+		}
+		if app.catalogHasForeignPrices() {
+			t.Fatal("в тестовом каталоге нет валютных цен")
+		}
+	}
+}
+
+func TestRetrieveRelevantProductsIgnoresQuantities(t *testing.T) {
+	catalog := append(testCatalog(), Product{ID: 4, Name: "Трос стальной 12 м", Unit: "шт", Price: Money{Kopecks: 99000, Currency: "RUB"}})
+	llm := &fakeLLMProvider{name: "fake", replies: []string{`{"keywords": ["лоток", "100х100"]}`}}
+	app := newTestApp(catalog, llm)
+
+	products, _ := app.retrieveRelevantProducts(context.Background(), "лоток 100х100 12 метров", 10)
+	if len(products) == 0 || products[0].ID != 1 {
+		t.Fatalf("первым должен быть найден лоток, получено %+v", products)
+	}
+	for _, p := range products {
+		if p.ID == 4 {
+			t.Errorf("количество «12 метров» не должно искать товары длиной 12 м: %+v", products)
+		}
+	}
+}
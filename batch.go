@@ -0,0 +1,409 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/unidoc/unioffice/v2/measurement"
+	"github.com/unidoc/unioffice/v2/spreadsheet"
+)
+
+const (
+	defaultBatchWorkers = 3
+	batchSummaryFile    = "summary.xlsx"
+)
+
+var batchColumnAliases = map[string][]string{
+	"query":    {"запрос", "запрос клиента", "заявка", "текст заявки", "query", "request"},
+	"name":     {"номер", "№", "название", "наименование", "name", "id"},
+	"customer": {"клиент", "заказчик", "покупатель", "customer"},
+	"currency": {"валюта", "currency"},
+}
+
+type BatchRequest struct {
+	Row       int             `json:"-"`
+	Name      string          `json:"name"`
+	Query     string          `json:"query"`
+	Customer  string          `json:"customer,omitempty"`
+	Currency  string          `json:"currency,omitempty"`
+	Overrides []PriceOverride `json:"overrides,omitempty"`
+}
+
+type BatchResult struct {
+	Request         BatchRequest
+	File            string
+	Items           int
+	TotalWithoutVAT Money
+	TotalVAT        Money
+	TotalCost       Money
+	Duration        time.Duration
+	Err             error
+}
+
+func batchRequestsFromTable(records [][]string, lines []int) []BatchRequest {
+	headerRow := -1
+	columns := map[string]int{"query": 0, "name": -1, "customer": -1, "currency": -1}
+	if len(records) > 0 {
+		if query := resolveColumn(records[0], "", batchColumnAliases["query"]); query >= 0 {
+			headerRow = 0
+			for key, aliases := range batchColumnAliases {
+				columns[key] = resolveColumn(records[0], "", aliases)
+			}
+		}
+	}
+	var requests []BatchRequest
+	for i := headerRow + 1; i < len(records); i++ {
+		record := records[i]
+		query := cellAt(record, columns["query"])
+		if query == "" {
+			continue
+		}
+		requests = append(requests, BatchRequest{
+			Row:      lines[i],
+			Name:     cellAt(record, columns["name"]),
+			Query:    query,
+			Customer: cellAt(record, columns["customer"]),
+			Currency: cellAt(record, columns["currency"]),
+		})
+	}
+	return requests
+}
+
+func loadBatchCSV(path string) ([]BatchRequest, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать '%s': %w", path, err)
+	}
+	text, _ := decodeCatalogText(raw, "auto")
+	records, lines, err := readCSVRecords(text, detectDelimiter(text))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка разбора '%s': %w", path, err)
+	}
+	return batchRequestsFromTable(records, lines), nil
+}
+
+func loadBatchXLSX(path, sheetSelector string) ([]BatchRequest, error) {
+	wb, err := spreadsheet.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть '%s': %w", path, err)
+	}
+	defer wb.Close()
+	sheets := wb.Sheets()
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("в книге '%s' нет ни одного листа", path)
+	}
+	sheet := sheets[0]
+	if sheetSelector != "" {
+		if sheet, err = selectXLSXSheet(wb, sheetSelector, CatalogColumns{}); err != nil {
+			return nil, fmt.Errorf("ошибка выбора листа в '%s': %w", path, err)
+		}
+	}
+	table := readXLSXSheet(sheet, path)
+	return batchRequestsFromTable(table.records, table.lines), nil
+}
+
+func loadBatchJSONL(path string) ([]BatchRequest, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть '%s': %w", path, err)
+	}
+	defer file.Close()
+	var requests []BatchRequest
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var request BatchRequest
+		if err := json.Unmarshal([]byte(text), &request); err != nil {
+			return nil, fmt.Errorf("'%s', строка %d: %w", path, line, err)
+		}
+		if strings.TrimSpace(request.Query) == "" {
+			continue
+		}
+		request.Row = line
+		requests = append(requests, request)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения '%s': %w", path, err)
+	}
+	return requests, nil
+}
+
+func loadBatchRequests(path, sheet string) ([]BatchRequest, error) {
+	var requests []BatchRequest
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv", ".tsv", ".txt":
+		requests, err = loadBatchCSV(path)
+	case ".xlsx":
+		requests, err = loadBatchXLSX(path, sheet)
+	case ".jsonl", ".ndjson":
+		requests, err = loadBatchJSONL(path)
+	default:
+		return nil, fmt.Errorf("неподдерживаемый формат файла заявок '%s': ожидается CSV, XLSX или JSONL", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("в '%s' не найдено ни одной заявки", path)
+	}
+	return requests, nil
+}
+
+func sanitizeFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case strings.ContainsRune("\"«»", r):
+			return -1
+		case strings.ContainsRune(`<>:/\|?*`, r) || r < 32:
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if runes := []rune(name); len(runes) > 60 {
+		name = string(runes[:60])
+	}
+	return strings.TrimRight(name, ". ")
+}
+
+func batchFileName(index int, request BatchRequest) string {
+	label := sanitizeFileName(strings.TrimSpace(request.Name + " " + request.Customer))
+	if label == "" {
+		label = "ТКП"
+	}
+	return fmt.Sprintf("%03d_%s.docx", index+1, label)
+}
+
+type batchReporter struct {
+	label string
+}
+
+func (r batchReporter) Progress(stage string, percent int, message string, artifact interface{}) {
+	log.Printf("[%s] %s (%d%%): %s", r.label, stage, percent, message)
+}
+
+func (r batchReporter) Stream(stage string, delta StreamDelta) {}
+
+func (a *App) runBatchRequest(ctx context.Context, index int, request BatchRequest, outDir string) BatchResult {
+	started := time.Now()
+	result := BatchResult{Request: request}
+	ctx = withProgressReporter(ctx, batchReporter{label: fmt.Sprintf("строка %d", request.Row)})
+	options := GenerationOptions{Customer: request.Customer, Currency: request.Currency, Overrides: request.Overrides}
+	built, err := a.buildProposal(ctx, request.Query, options)
+	if err == nil {
+		var docx []byte
+		docx, err = a.renderProposal(ctx, request.Query, options, built)
+		if err == nil {
+			result.File = batchFileName(index, request)
+			if writeErr := os.WriteFile(filepath.Join(outDir, result.File), docx, 0644); writeErr != nil {
+				err = fmt.Errorf("не удалось записать '%s': %w", result.File, writeErr)
+				result.File = ""
+			}
+		}
+	}
+	result.Items = len(built.totals.FoundItems)
+	result.TotalWithoutVAT = built.totals.TotalWithoutVAT
+	result.TotalVAT = built.totals.TotalVAT
+	result.TotalCost = built.totals.TotalCost
+	result.Duration = time.Since(started)
+	result.Err = err
+	return result
+}
+
+func (a *App) runBatch(ctx context.Context, requests []BatchRequest, outDir string, workers int) ([]BatchResult, error) {
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return nil, fmt.Errorf("не удалось создать каталог '%s': %w", outDir, err)
+	}
+	if err := a.ensureDataIsLoaded(ctx); err != nil {
+		return nil, err
+	}
+	if workers <= 0 {
+		workers = defaultBatchWorkers
+	}
+	log.Printf("Пакетная генерация: %d заявок, параллельно %d.", len(requests), workers)
+
+	results := make([]BatchResult, len(requests))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				if err := ctx.Err(); err != nil {
+					results[index] = BatchResult{Request: requests[index], Err: err}
+					continue
+				}
+				results[index] = a.runBatchRequest(ctx, index, requests[index], outDir)
+				if results[index].Err != nil {
+					log.Printf("ПРЕДУПРЕЖДЕНИЕ: заявка из строки %d не обработана: %v", requests[index].Row, results[index].Err)
+				}
+			}
+		}()
+	}
+	for index := range requests {
+		jobs <- index
+	}
+	close(jobs)
+	wg.Wait()
+	return results, nil
+}
+
+func batchTotals(results []BatchResult) ([]Money, error) {
+	var totals []Money
+	index := make(map[string]int)
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+		currency := result.TotalCost.Currency
+		i, ok := index[currency]
+		if !ok {
+			i = len(totals)
+			index[currency] = i
+			totals = append(totals, Money{Currency: currency})
+		}
+		total, err := totals[i].checkedAdd(result.TotalCost)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка расчета итогов сводки: %w", err)
+		}
+		totals[i] = total
+	}
+	return totals, nil
+}
+
+func writeBatchSummary(path string, results []BatchResult) error {
+	wb := spreadsheet.New()
+	defer wb.Close()
+	sheet := wb.AddSheet()
+	sheet.SetName("Сводка")
+	header := sheet.AddRow()
+	for _, title := range []string{"№", "Строка", "Название", "Клиент", "Запрос", "Статус", "Позиций", "Сумма без НДС", "НДС", "Итого", "Валюта", "Файл", "Ошибка"} {
+		header.AddCell().SetString(title)
+	}
+	failed := 0
+	for i, result := range results {
+		row := sheet.AddRow()
+		row.AddCell().SetNumber(float64(i + 1))
+		row.AddCell().SetNumber(float64(result.Request.Row))
+		row.AddCell().SetString(result.Request.Name)
+		row.AddCell().SetString(result.Request.Customer)
+		row.AddCell().SetString(result.Request.Query)
+		if result.Err != nil {
+			failed++
+			row.AddCell().SetString("Ошибка")
+			for j := 0; j < 6; j++ {
+				row.AddCell()
+			}
+			row.AddCell().SetString(result.Err.Error())
+			continue
+		}
+		row.AddCell().SetString("Готово")
+		row.AddCell().SetNumber(float64(result.Items))
+		row.AddCell().SetNumberWithStyle(result.TotalWithoutVAT.Float(), spreadsheet.StandardFormat4)
+		row.AddCell().SetNumberWithStyle(result.TotalVAT.Float(), spreadsheet.StandardFormat4)
+		row.AddCell().SetNumberWithStyle(result.TotalCost.Float(), spreadsheet.StandardFormat4)
+		row.AddCell().SetString(result.TotalCost.Currency)
+		row.AddCell().SetString(result.File)
+	}
+
+	totals, err := batchTotals(results)
+	if err != nil {
+		return err
+	}
+	sheet.AddRow()
+	for _, total := range totals {
+		row := sheet.AddRow()
+		row.AddCell().SetString(fmt.Sprintf("Итого, %s", total.Currency))
+		for j := 0; j < 8; j++ {
+			row.AddCell()
+		}
+		row.AddCell().SetNumberWithStyle(total.Float(), spreadsheet.StandardFormat4)
+		row.AddCell().SetString(total.Currency)
+	}
+	row := sheet.AddRow()
+	row.AddCell().SetString(fmt.Sprintf("Обработано: %d, с ошибками: %d", len(results)-failed, failed))
+
+	for idx, width := range []measurement.Distance{5, 8, 20, 25, 60, 10, 9, 16, 14, 16, 8, 30, 60} {
+		sheet.Column(uint32(idx + 1)).SetWidth(width * measurement.Character)
+	}
+	if err := wb.SaveToFile(path); err != nil {
+		return fmt.Errorf("не удалось сохранить сводку '%s': %w", path, err)
+	}
+	return nil
+}
+
+func runBatchCommand(args []string) int {
+	flags := flag.NewFlagSet("batch", flag.ContinueOnError)
+	outDir := flags.String("out-dir", "", "каталог для DOCX и сводки (по умолчанию batch_<время>)")
+	workers := flags.Int("workers", defaultBatchWorkers, "сколько заявок обрабатывать одновременно")
+	sheet := flags.String("sheet", "", "лист XLSX с заявками (номер или название)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Использование: tkp batch [--workers 3] [--out-dir каталог] заявки.xlsx|csv|jsonl")
+		return 2
+	}
+	if err := activateLicense(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	requests, err := loadBatchRequests(flags.Arg(0), *sheet)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	dir := *outDir
+	if dir == "" {
+		dir = fmt.Sprintf("batch_%d", time.Now().Unix())
+	}
+
+	ctx, cancel := cliContext()
+	defer cancel()
+	app := newCLIApp()
+	results, err := app.runBatch(ctx, requests, dir, *workers)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка пакетной генерации: %v\n", err)
+		return 1
+	}
+	summaryPath := filepath.Join(dir, batchSummaryFile)
+	if err := writeBatchSummary(summaryPath, results); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Строка\tФайл\tИтого\tВремя")
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			fmt.Fprintf(w, "%d\tошибка: %v\t\t\n", result.Request.Row, result.Err)
+			continue
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", result.Request.Row, result.File, result.TotalCost, result.Duration.Round(time.Second))
+	}
+	w.Flush()
+	fmt.Printf("Готово: %d из %d, сводка: '%s'.\n", len(results)-failed, len(results), summaryPath)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
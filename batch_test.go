@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/unidoc/unioffice/v2/spreadsheet"
+)
+
+func TestBatchRequestsFromTable(t *testing.T) {
+	tests := []struct {
+		name    string
+		records [][]string
+		want    []BatchRequest
+	}{
+		{
+			name: "заголовок с переставленными колонками",
+			records: [][]string{
+				{"Клиент", "№", "Текст заявки", "Валюта"},
+				{"ООО Ромашка", "З-1", "лоток 100х100 12 м", "EUR"},
+				{"ООО Лютик", "З-2", "  ", ""},
+				{"", "З-3", "гайки М10 200 шт", ""},
+			},
+			want: []BatchRequest{
+				{Row: 2, Name: "З-1", Query: "лоток 100х100 12 м", Customer: "ООО Ромашка", Currency: "EUR"},
+				{Row: 4, Name: "З-3", Query: "гайки М10 200 шт"},
+			},
+		},
+		{
+			name: "без заголовка берется первая колонка",
+			records: [][]string{
+				{"лоток 100х100 12 м", "ООО Ромашка"},
+				{"гайки М10 200 шт"},
+				{""},
+			},
+			want: []BatchRequest{
+				{Row: 1, Query: "лоток 100х100 12 м"},
+				{Row: 2, Query: "гайки М10 200 шт"},
+			},
+		},
+		{
+			name:    "пустая таблица",
+			records: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := make([]int, len(tt.records))
+			for i := range lines {
+				lines[i] = i + 1
+			}
+			if got := batchRequestsFromTable(tt.records, lines); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("заявки = %+v, ожидалось %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"ООО «Ромашка»", "ООО Ромашка"},
+		{`З-1/2: "срочно"?`, "З-1_2_ срочно_"},
+		{"  заявка.  ", "заявка"},
+		{"строка\tс\nуправляющими", "строка_с_управляющими"},
+		{"...", ""},
+		{"ТКП для ООО Ромашка по заявке от 17 октября 2026 года на поставку лотков и крышек", "ТКП для ООО Ромашка по заявке от 17 октября 2026 года на пос"},
+	}
+	for _, tt := range tests {
+		if got := sanitizeFileName(tt.name); got != tt.want {
+			t.Errorf("sanitizeFileName(%q) = %q, ожидалось %q", tt.name, got, tt.want)
+		}
+	}
+	if got := batchFileName(4, BatchRequest{Customer: "«»"}); got != "005_ТКП.docx" {
+		t.Errorf("имя файла без названия = %q", got)
+	}
+}
+
+func testBatchResults() []BatchResult {
+	return []BatchResult{
+		{Request: BatchRequest{Row: 2, Name: "З-1"}, File: "001_З-1.docx", Items: 2, TotalCost: Money{Kopecks: 120000, Currency: "RUB"}, TotalVAT: Money{Kopecks: 20000, Currency: "RUB"}, TotalWithoutVAT: Money{Kopecks: 100000, Currency: "RUB"}},
+		{Request: BatchRequest{Row: 3, Name: "З-2"}, File: "002_З-2.docx", Items: 1, TotalCost: Money{Kopecks: 5050, Currency: "EUR"}},
+		{Request: BatchRequest{Row: 4, Name: "З-3"}, Err: errors.New("нет сети"), TotalCost: Money{Kopecks: 999999, Currency: "RUB"}},
+		{Request: BatchRequest{Row: 5, Name: "З-4"}, File: "004_З-4.docx", Items: 3, TotalCost: Money{Kopecks: 30001, Currency: "RUB"}},
+	}
+}
+
+func TestBatchTotals(t *testing.T) {
+	totals, err := batchTotals(testBatchResults())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Money{{Kopecks: 150001, Currency: "RUB"}, {Kopecks: 5050, Currency: "EUR"}}
+	if !reflect.DeepEqual(totals, want) {
+		t.Errorf("итоги = %v, ожидалось %v", totals, want)
+	}
+	if totals, err := batchTotals(nil); err != nil || len(totals) != 0 {
+		t.Errorf("итоги пустого прогона = %v, %v", totals, err)
+	}
+}
+
+func TestWriteBatchSummary(t *testing.T) {
+	if err := activateLicense(); err != nil {
+		t.Skipf("лицензия UniOffice недоступна: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), batchSummaryFile)
+	if err := writeBatchSummary(path, testBatchResults()); err != nil {
+		t.Fatal(err)
+	}
+	wb, err := spreadsheet.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wb.Close()
+	table := readXLSXSheet(wb.Sheets()[0], path)
+	rows := make(map[string][]string)
+	for _, record := range table.records {
+		if len(record) > 0 && record[0] != "" {
+			rows[record[0]] = record
+		}
+	}
+	if failed := table.records[3]; len(failed) < 13 || failed[5] != "Ошибка" || failed[12] != "нет сети" {
+		t.Errorf("строка с ошибкой = %q", failed)
+	}
+	if rub := rows["Итого, RUB"]; len(rub) < 11 || rub[9] != "1500.01" || rub[10] != "RUB" {
+		t.Errorf("итог в рублях = %q", rub)
+	}
+	if eur := rows["Итого, EUR"]; len(eur) < 11 || eur[9] != "50.5" || eur[10] != "EUR" {
+		t.Errorf("итог в евро = %q", eur)
+	}
+	if _, ok := rows["Обработано: 3, с ошибками: 1"]; !ok {
+		t.Errorf("нет строки с количеством обработанных заявок: %q", table.records)
+	}
+}
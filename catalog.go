@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const importReportFile = "import_report.json"
+
+type ImportIssue struct {
+	Line   int     `json:"line,omitempty"`
+	Text   string  `json:"text,omitempty"`
+	Name   string  `json:"name,omitempty"`
+	Price  float64 `json:"price,omitempty"`
+	Reason string  `json:"reason"`
+}
+
+type ImportReport struct {
+	Source     string        `json:"source"`
+	ImportedAt time.Time     `json:"importedAt"`
+	Imported   int           `json:"imported"`
+	Partial    bool          `json:"partial,omitempty"`
+	Skipped    []ImportIssue `json:"skipped,omitempty"`
+	Invented   []ImportIssue `json:"invented,omitempty"`
+	Ignored    []ImportIssue `json:"ignored,omitempty"`
+	Deleted    []string      `json:"deleted,omitempty"`
+	PriceOnly  []string      `json:"priceOnly,omitempty"`
+	Diff       *CatalogDiff  `json:"diff,omitempty"`
+}
+
+func newImportReport(source string) *ImportReport {
+	return &ImportReport{Source: source, ImportedAt: time.Now()}
+}
+
+func (r *ImportReport) addSkipped(line sourceLine, reason string) {
+	r.Skipped = append(r.Skipped, ImportIssue{Line: line.Line, Text: line.Text, Reason: reason})
+}
+
+func (r *ImportReport) save() error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("не удалось сформировать JSON отчета об импорте: %w", err)
+	}
+	if err := os.WriteFile(importReportFile, data, 0644); err != nil {
+		return fmt.Errorf("не удалось записать '%s': %w", importReportFile, err)
+	}
+	log.Printf("Отчет об импорте сохранен в '%s': импортировано %d, пропущено %d, отброшено выдуманных %d, без товара %d.", importReportFile, r.Imported, len(r.Skipped), len(r.Invented), len(r.Ignored))
+	return nil
+}
+
+func (c CatalogConfig) sourcePath() string {
+	if c.Source == "" {
+		return "materials.csv"
+	}
+	return c.Source
+}
+
+func (a *App) importCatalog(ctx context.Context, path string, report *ImportReport) ([]Product, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv", ".tsv", ".txt":
+		return a.importCSVCatalog(ctx, path, report)
+	case ".xlsx":
+		return a.importXLSXCatalog(ctx, path, report)
+	case ".xml":
+		return a.importCommerceMLCatalog(ctx, path, report)
+	default:
+		return nil, fmt.Errorf("неподдерживаемый формат каталога '%s'", path)
+	}
+}
+
+func (a *App) catalogSourceChanged() bool {
+	source, err := os.Stat(a.config.Catalog.sourcePath())
+	if err != nil {
+		return false
+	}
+	cache, err := os.Stat("products.json")
+	if err != nil {
+		return true
+	}
+	if source.ModTime().After(cache.ModTime()) {
+		return true
+	}
+	if offersFile := a.config.Catalog.offersPath(a.config.Catalog.sourcePath()); offersFile != "" {
+		if offers, err := os.Stat(offersFile); err == nil && offers.ModTime().After(cache.ModTime()) {
+			return true
+		}
+	}
+	return false
+}
+
+func mergePartialImport(previous, imported []Product, report *ImportReport) []Product {
+	old := make(map[string]Product, len(previous))
+	for _, p := range previous {
+		old[p.Key] = p
+	}
+	removed := make(map[string]bool, len(report.Deleted))
+	for _, id := range report.Deleted {
+		removed[id] = true
+	}
+	priceOnly := make(map[string]bool, len(report.PriceOnly))
+	for _, id := range report.PriceOnly {
+		priceOnly[id] = true
+	}
+
+	updated := make(map[string]bool, len(imported))
+	changes := make([]Product, 0, len(imported))
+	for _, p := range imported {
+		updated[p.Key] = true
+		if before, ok := old[p.Key]; ok && priceOnly[p.ExternalID] {
+			before.Price = p.Price
+			if p.Stock != nil {
+				before.Stock = p.Stock
+			}
+			if p.Cost != nil {
+				before.Cost = p.Cost
+			}
+			if p.PackSize > 0 {
+				before.PackSize = p.PackSize
+			}
+			p = before
+		}
+		changes = append(changes, p)
+	}
+	merged := make([]Product, 0, len(previous)+len(changes))
+	for _, p := range previous {
+		if updated[p.Key] || (p.ExternalID != "" && removed[commerceMLProductID(p.ExternalID)]) {
+			continue
+		}
+		merged = append(merged, p)
+	}
+	return append(merged, changes...)
+}
+
+func (a *App) ReimportCatalog() (CatalogDiff, error) {
+	a.dataLoadMutex.Lock()
+	defer a.dataLoadMutex.Unlock()
+	log.Printf("Запрошен повторный импорт каталога '%s'...", a.config.Catalog.sourcePath())
+	return a.reimportCatalogLocked(a.baseContext())
+}
+
+func (a *App) reimportCatalogLocked(ctx context.Context) (CatalogDiff, error) {
+	source := a.config.Catalog.sourcePath()
+	report := newImportReport(source)
+	parsedProducts, err := a.importCatalog(ctx, source, report)
+	if err != nil {
+		return CatalogDiff{}, err
+	}
+	if len(parsedProducts) == 0 && !(report.Partial && len(report.Deleted) > 0) {
+		return CatalogDiff{}, fmt.Errorf("в '%s' не найдено ни одного товара", source)
+	}
+	previous := a.currentCatalog().products
+	registry, err := loadCatalogIDRegistry(previous)
+	if err != nil {
+		return CatalogDiff{}, err
+	}
+	finalProducts := registry.assign(parsedProducts)
+	if report.Partial {
+		finalProducts = mergePartialImport(previous, finalProducts, report)
+	}
+	if err := ctx.Err(); err != nil {
+		return CatalogDiff{}, err
+	}
+	diff := diffCatalogs(previous, finalProducts)
+	finalJSONData, err := json.MarshalIndent(finalProducts, "", "  ")
+	if err != nil {
+		return CatalogDiff{}, fmt.Errorf("ошибка финальной сериализации: %w", err)
+	}
+	if err := os.WriteFile("products.json", finalJSONData, 0644); err != nil {
+		return CatalogDiff{}, fmt.Errorf("не удалось сохранить кэш в 'products.json': %w", err)
+	}
+	if err := registry.save(); err != nil {
+		return CatalogDiff{}, err
+	}
+	log.Println("Данные успешно распарсены и сохранены в 'products.json'.")
+	diff.logSummary()
+	report.Imported = len(finalProducts)
+	report.Diff = &diff
+	if err := report.save(); err != nil {
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: %v", err)
+	}
+	a.loadProductsFromCache()
+	if len(a.currentCatalog().products) == 0 {
+		return CatalogDiff{}, fmt.Errorf("не удалось загрузить данные в память даже после парсинга")
+	}
+	return diff, nil
+}
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+type cmlDocument struct {
+	XMLName    xml.Name          `xml:"КоммерческаяИнформация"`
+	Classifier *cmlClassifier    `xml:"Классификатор"`
+	Catalog    *cmlCatalog       `xml:"Каталог"`
+	Offers     *cmlOffersPackage `xml:"ПакетПредложений"`
+}
+
+type cmlGroup struct {
+	ID     string     `xml:"Ид"`
+	Name   string     `xml:"Наименование"`
+	Groups []cmlGroup `xml:"Группы>Группа"`
+}
+
+type cmlPriceType struct {
+	ID       string `xml:"Ид"`
+	Name     string `xml:"Наименование"`
+	Currency string `xml:"Валюта"`
+}
+
+type cmlClassifier struct {
+	Groups     []cmlGroup     `xml:"Группы>Группа"`
+	PriceTypes []cmlPriceType `xml:"ТипыЦен>ТипЦены"`
+}
+
+type cmlUnit struct {
+	Code      string `xml:"Код,attr"`
+	FullName  string `xml:"НаименованиеПолное,attr"`
+	ShortName string `xml:"НаименованиеКраткое,attr"`
+	Value     string `xml:",chardata"`
+}
+
+type cmlTaxRate struct {
+	Name string `xml:"Наименование"`
+	Rate string `xml:"Ставка"`
+}
+
+type cmlProduct struct {
+	ID             string       `xml:"Ид"`
+	Article        string       `xml:"Артикул"`
+	Name           string       `xml:"Наименование"`
+	BaseUnit       cmlUnit      `xml:"БазоваяЕдиница"`
+	GroupIDs       []string     `xml:"Группы>Ид"`
+	TaxRates       []cmlTaxRate `xml:"СтавкиНалогов>СтавкаНалога"`
+	MarkedToDelete bool         `xml:"ПометкаУдаления"`
+}
+
+type cmlCatalog struct {
+	ChangesOnly bool         `xml:"СодержитТолькоИзменения,attr"`
+	Products    []cmlProduct `xml:"Товары>Товар"`
+}
+
+type cmlPrice struct {
+	PriceTypeID  string `xml:"ИдТипаЦены"`
+	PricePerUnit string `xml:"ЦенаЗаЕдиницу"`
+	Currency     string `xml:"Валюта"`
+	Unit         string `xml:"Единица"`
+	Ratio        string `xml:"Коэффициент"`
+}
+
+type cmlOffer struct {
+	ID       string     `xml:"Ид"`
+	Article  string     `xml:"Артикул"`
+	Name     string     `xml:"Наименование"`
+	BaseUnit cmlUnit    `xml:"БазоваяЕдиница"`
+	Prices   []cmlPrice `xml:"Цены>Цена"`
+	Quantity string     `xml:"Количество"`
+}
+
+type cmlOffersPackage struct {
+	ChangesOnly bool           `xml:"СодержитТолькоИзменения,attr"`
+	PriceTypes  []cmlPriceType `xml:"ТипыЦен>ТипЦены"`
+	Offers      []cmlOffer     `xml:"Предложения>Предложение"`
+}
+
+func xmlCharsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(strings.ReplaceAll(charset, "-", "")) {
+	case "windows1251", "cp1251":
+		return charmap.Windows1251.NewDecoder().Reader(input), nil
+	case "utf8", "":
+		return input, nil
+	}
+	return nil, fmt.Errorf("неподдерживаемая кодировка XML: %s", charset)
+}
+
+func readCommerceMLFile(path string) (*cmlDocument, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть '%s': %w", path, err)
+	}
+	defer file.Close()
+	decoder := xml.NewDecoder(file)
+	decoder.CharsetReader = xmlCharsetReader
+	var doc cmlDocument
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("ошибка разбора CommerceML '%s': %w", path, err)
+	}
+	return &doc, nil
+}
+
+func (u cmlUnit) label() string {
+	if unit, ok := unitAliases[u.Code]; ok && strings.Trim(strings.TrimSpace(u.Value), "0123456789") == "" {
+		return unit
+	}
+	value := strings.TrimSpace(u.Value)
+	if value != "" && strings.Trim(value, "0123456789") != "" {
+		return value
+	}
+	if u.ShortName != "" {
+		return u.ShortName
+	}
+	if u.FullName != "" {
+		return u.FullName
+	}
+	return value
+}
+
+func flattenCommerceMLGroups(groups []cmlGroup, parent string, paths map[string]string) {
+	for _, group := range groups {
+		path := strings.TrimSpace(group.Name)
+		if parent != "" {
+			path = parent + " / " + path
+		}
+		paths[group.ID] = path
+		flattenCommerceMLGroups(group.Groups, path, paths)
+	}
+}
+
+func selectCommerceMLPriceType(types []cmlPriceType, selector string) (cmlPriceType, error) {
+	if len(types) == 0 {
+		return cmlPriceType{}, nil
+	}
+	if selector == "" {
+		return types[0], nil
+	}
+	for _, t := range types {
+		if t.ID == selector || strings.EqualFold(strings.TrimSpace(t.Name), strings.TrimSpace(selector)) {
+			return t, nil
+		}
+	}
+	return cmlPriceType{}, fmt.Errorf("тип цены '%s' не найден в CommerceML", selector)
+}
+
+func commerceMLProductID(offerID string) string {
+	if idx := strings.Index(offerID, "#"); idx >= 0 {
+		return offerID[:idx]
+	}
+	return offerID
+}
+
+func (c CatalogConfig) offersPath(importPath string) string {
+	if c.Offers != "" {
+		return c.Offers
+	}
+	candidate := filepath.Join(filepath.Dir(importPath), "offers.xml")
+	if _, err := os.Stat(candidate); err == nil && !strings.EqualFold(filepath.Clean(candidate), filepath.Clean(importPath)) {
+		return candidate
+	}
+	return ""
+}
+
+func (a *App) importCommerceMLCatalog(ctx context.Context, path string, report *ImportReport) ([]Product, error) {
+	doc, err := readCommerceMLFile(path)
+	if err != nil {
+		return nil, err
+	}
+	offers := doc.Offers
+	if offersFile := a.config.Catalog.offersPath(path); offersFile != "" {
+		offersDoc, err := readCommerceMLFile(offersFile)
+		if err != nil {
+			return nil, err
+		}
+		if offersDoc.Offers != nil {
+			offers = offersDoc.Offers
+		}
+		log.Printf("Импорт CommerceML: предложения загружены из '%s'.", offersFile)
+	}
+	if doc.Catalog == nil && offers == nil {
+		return nil, fmt.Errorf("'%s' не содержит ни каталога, ни пакета предложений CommerceML", path)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	groupPaths := make(map[string]string)
+	var priceTypes []cmlPriceType
+	if doc.Classifier != nil {
+		flattenCommerceMLGroups(doc.Classifier.Groups, "", groupPaths)
+		priceTypes = doc.Classifier.PriceTypes
+	}
+	if offers != nil && len(offers.PriceTypes) > 0 {
+		priceTypes = offers.PriceTypes
+	}
+	priceType, err := selectCommerceMLPriceType(priceTypes, a.config.Catalog.PriceType)
+	if err != nil {
+		return nil, err
+	}
+	if priceType.ID != "" {
+		log.Printf("Импорт CommerceML: используется тип цены '%s' (%s).", priceType.Name, priceType.ID)
+	}
+	var costType cmlPriceType
+	if a.config.Catalog.CostPriceType != "" {
+		if costType, err = selectCommerceMLPriceType(priceTypes, a.config.Catalog.CostPriceType); err != nil {
+			return nil, err
+		}
+	}
+
+	report.Partial = (doc.Catalog != nil && doc.Catalog.ChangesOnly) || (doc.Catalog == nil && offers != nil && offers.ChangesOnly)
+	catalogProducts := make(map[string]cmlProduct)
+	deleted := make(map[string]bool)
+	var order []string
+	if doc.Catalog != nil {
+		for _, p := range doc.Catalog.Products {
+			if p.MarkedToDelete {
+				report.Ignored = append(report.Ignored, ImportIssue{Name: p.Name, Text: p.ID, Reason: "товар помечен на удаление в 1С"})
+				report.Deleted = append(report.Deleted, p.ID)
+				deleted[p.ID] = true
+				continue
+			}
+			catalogProducts[p.ID] = p
+			order = append(order, p.ID)
+		}
+	}
+
+	var products []Product
+	priced := make(map[string]bool)
+	if offers != nil {
+		for _, offer := range offers.Offers {
+			if deleted[commerceMLProductID(offer.ID)] {
+				continue
+			}
+			price, ok := offerPrice(offer, priceType)
+			if !ok {
+				report.Skipped = append(report.Skipped, ImportIssue{Name: offer.Name, Text: offer.ID, Reason: "у предложения нет цены выбранного типа"})
+				continue
+			}
+			base, known := catalogProducts[commerceMLProductID(offer.ID)]
+			if !known && doc.Catalog != nil && !doc.Catalog.ChangesOnly {
+				report.Skipped = append(report.Skipped, ImportIssue{Name: offer.Name, Text: offer.ID, Reason: "предложение ссылается на товар, отсутствующий в каталоге"})
+				continue
+			}
+			product := commerceMLProduct(base, offer, groupPaths, price, priceType.ID)
+			if costType.ID != "" {
+				if cost, ok := offerPrice(offer, costType); ok {
+					product.Cost = &cost
+				}
+			}
+			if !known {
+				report.PriceOnly = append(report.PriceOnly, offer.ID)
+			}
+			products = append(products, product)
+			priced[commerceMLProductID(offer.ID)] = true
+		}
+	}
+	for _, id := range order {
+		if !priced[id] {
+			p := catalogProducts[id]
+			report.Skipped = append(report.Skipped, ImportIssue{Name: p.Name, Text: p.ID, Reason: "для товара нет предложения с ценой"})
+		}
+	}
+	log.Printf("Импорт CommerceML '%s': товаров в каталоге %d, импортировано с ценой %d, помечено на удаление %d.", path, len(order), len(products), len(report.Deleted))
+	return products, nil
+}
+
+func offerPrice(offer cmlOffer, priceType cmlPriceType) (Money, bool) {
+	for _, p := range offer.Prices {
+		if priceType.ID != "" && p.PriceTypeID != priceType.ID {
+			continue
+		}
+		if value, ok := parsePriceValue(p.PricePerUnit); ok {
+			currency := p.Currency
+			if currency == "" {
+				currency = priceType.Currency
+			}
+			return moneyFromFloat(value, currency), true
+		}
+	}
+	return Money{}, false
+}
+
+func commerceMLProduct(base cmlProduct, offer cmlOffer, groupPaths map[string]string, price Money, priceTypeID string) Product {
+	product := Product{
+		ExternalID: offer.ID,
+		Name:       strings.TrimSpace(offer.Name),
+		Article:    strings.TrimSpace(offer.Article),
+		Unit:       normalizeUnit(offer.BaseUnit.label()),
+		Price:      price,
+		Stock:      parseStockValue(offer.Quantity),
+		PackSize:   offerPackSize(offer, priceTypeID),
+		VATRate:    commerceMLVATRate(base.TaxRates),
+	}
+	if product.Name == "" {
+		product.Name = strings.TrimSpace(base.Name)
+	}
+	if product.Article == "" {
+		product.Article = strings.TrimSpace(base.Article)
+	}
+	if product.Unit == "" {
+		product.Unit = normalizeUnit(base.BaseUnit.label())
+	}
+	for _, groupID := range base.GroupIDs {
+		if path, ok := groupPaths[groupID]; ok {
+			product.Category = path
+			break
+		}
+	}
+	return product
+}
+
+func offerPackSize(offer cmlOffer, priceTypeID string) float64 {
+	for _, p := range offer.Prices {
+		if priceTypeID != "" && p.PriceTypeID != priceTypeID {
+			continue
+		}
+		if size := parsePackSize(p.Ratio); size > 1 {
+			return size
+		}
+	}
+	return 0
+}
+
+func commerceMLVATRate(rates []cmlTaxRate) *int {
+	for _, rate := range rates {
+		if strings.EqualFold(strings.TrimSpace(rate.Name), "НДС") {
+			return parseVATRate(rate.Rate)
+		}
+	}
+	return nil
+}
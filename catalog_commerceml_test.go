@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testCommerceMLImport = `<?xml version="1.0" encoding="UTF-8"?>
+<КоммерческаяИнформация ВерсияСхемы="2.10">
+  <Классификатор>
+    <Группы>
+      <Группа>
+        <Ид>g1</Ид>
+        <Наименование>Кабеленесущие системы</Наименование>
+        <Группы>
+          <Группа><Ид>g2</Ид><Наименование>Лотки</Наименование></Группа>
+        </Группы>
+      </Группа>
+    </Группы>
+  </Классификатор>
+  <Каталог СодержитТолькоИзменения="false">
+    <Товары>
+      <Товар>
+        <Ид>p1</Ид>
+        <Артикул>LP-100</Артикул>
+        <Наименование>Лоток перфорированный 100х100</Наименование>
+        <БазоваяЕдиница Код="006" НаименованиеПолное="Метр">м</БазоваяЕдиница>
+        <Группы><Ид>g2</Ид></Группы>
+        <СтавкиНалогов><СтавкаНалога><Наименование>НДС</Наименование><Ставка>20</Ставка></СтавкаНалога></СтавкиНалогов>
+      </Товар>
+      <Товар>
+        <Ид>p2</Ид>
+        <Наименование>Гайка М10</Наименование>
+        <БазоваяЕдиница Код="796" НаименованиеКраткое="шт">796</БазоваяЕдиница>
+        <СтавкиНалогов><СтавкаНалога><Наименование>НДС</Наименование><Ставка>Без НДС</Ставка></СтавкаНалога></СтавкиНалогов>
+      </Товар>
+      <Товар>
+        <Ид>p3</Ид>
+        <Наименование>Снятый с продажи короб</Наименование>
+        <ПометкаУдаления>true</ПометкаУдаления>
+      </Товар>
+      <Товар>
+        <Ид>p4</Ид>
+        <Наименование>Крышка без цены</Наименование>
+      </Товар>
+    </Товары>
+  </Каталог>
+</КоммерческаяИнформация>`
+
+const testCommerceMLOffers = `<?xml version="1.0" encoding="UTF-8"?>
+<КоммерческаяИнформация ВерсияСхемы="2.10">
+  <ПакетПредложений СодержитТолькоИзменения="false">
+    <ТипыЦен>
+      <ТипЦены><Ид>retail</Ид><Наименование>Розничная</Наименование><Валюта>RUB</Валюта></ТипЦены>
+      <ТипЦены><Ид>opt</Ид><Наименование>Оптовая</Наименование><Валюта>RUB</Валюта></ТипЦены>
+      <ТипЦены><Ид>cost</Ид><Наименование>Закупочная</Наименование><Валюта>RUB</Валюта></ТипЦены>
+    </ТипыЦен>
+    <Предложения>
+      <Предложение>
+        <Ид>p1</Ид>
+        <Наименование>Лоток перфорированный 100х100</Наименование>
+        <Цены>
+          <Цена><ИдТипаЦены>retail</ИдТипаЦены><ЦенаЗаЕдиницу>500</ЦенаЗаЕдиницу></Цена>
+          <Цена><ИдТипаЦены>opt</ИдТипаЦены><ЦенаЗаЕдиницу>450.50</ЦенаЗаЕдиницу></Цена>
+          <Цена><ИдТипаЦены>cost</ИдТипаЦены><ЦенаЗаЕдиницу>300</ЦенаЗаЕдиницу></Цена>
+        </Цены>
+        <Количество>120</Количество>
+      </Предложение>
+      <Предложение>
+        <Ид>p2#black</Ид>
+        <Наименование>Гайка М10 черная</Наименование>
+        <Цены>
+          <Цена><ИдТипаЦены>opt</ИдТипаЦены><ЦенаЗаЕдиницу>3,20</ЦенаЗаЕдиницу><Коэффициент>100</Коэффициент></Цена>
+        </Цены>
+      </Предложение>
+      <Предложение>
+        <Ид>p4</Ид>
+        <Наименование>Крышка без цены</Наименование>
+        <Цены>
+          <Цена><ИдТипаЦены>retail</ИдТипаЦены><ЦенаЗаЕдиницу>210</ЦенаЗаЕдиницу></Цена>
+        </Цены>
+      </Предложение>
+      <Предложение>
+        <Ид>p9</Ид>
+        <Наименование>Чужое предложение</Наименование>
+        <Цены>
+          <Цена><ИдТипаЦены>opt</ИдТипаЦены><ЦенаЗаЕдиницу>1</ЦенаЗаЕдиницу></Цена>
+        </Цены>
+      </Предложение>
+    </Предложения>
+  </ПакетПредложений>
+</КоммерческаяИнформация>`
+
+func TestImportCommerceMLCatalog(t *testing.T) {
+	dir := t.TempDir()
+	importPath := filepath.Join(dir, "import.xml")
+	offersPath := filepath.Join(dir, "offers.xml")
+	if err := os.WriteFile(importPath, []byte(testCommerceMLImport), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(offersPath, []byte(testCommerceMLOffers), 0644); err != nil {
+		t.Fatal(err)
+	}
+	app := &App{config: Config{Catalog: CatalogConfig{PriceType: "оптовая", CostPriceType: "cost"}}}
+	report := newImportReport(importPath)
+
+	products, err := app.importCommerceMLCatalog(context.Background(), importPath, report)
+	if err != nil {
+		t.Fatalf("importCommerceMLCatalog: %v", err)
+	}
+	if len(products) != 2 {
+		t.Fatalf("ожидалось 2 товара, получено %+v", products)
+	}
+
+	tray := products[0]
+	if tray.ExternalID != "p1" || tray.Article != "LP-100" || tray.Unit != "м" || tray.Category != "Кабеленесущие системы / Лотки" {
+		t.Errorf("лоток = %+v", tray)
+	}
+	if tray.Price != (Money{Kopecks: 45050, Currency: "RUB"}) || tray.Cost == nil || tray.Cost.Kopecks != 30000 {
+		t.Errorf("цена лотка = %v, себестоимость = %v", tray.Price, tray.Cost)
+	}
+	if tray.Stock == nil || *tray.Stock != 120 || tray.VATRate == nil || *tray.VATRate != 20 {
+		t.Errorf("остаток = %v, НДС = %v", tray.Stock, tray.VATRate)
+	}
+
+	nut := products[1]
+	if nut.ExternalID != "p2#black" || nut.Name != "Гайка М10 черная" || nut.Unit != "шт" || nut.PackSize != 100 || nut.Price.Kopecks != 320 {
+		t.Errorf("гайка = %+v", nut)
+	}
+	if nut.Stock != nil || nut.Cost != nil || nut.VATRate == nil || *nut.VATRate != 0 {
+		t.Errorf("гайка: остаток = %v, себестоимость = %v, НДС = %v", nut.Stock, nut.Cost, nut.VATRate)
+	}
+
+	if report.Partial {
+		t.Error("полная выгрузка помечена как частичная")
+	}
+	if len(report.Ignored) != 1 || report.Ignored[0].Text != "p3" || !reflect.DeepEqual(report.Deleted, []string{"p3"}) {
+		t.Errorf("ignored = %+v, deleted = %v", report.Ignored, report.Deleted)
+	}
+	wantSkipped := []ImportIssue{
+		{Text: "p4", Name: "Крышка без цены", Reason: "у предложения нет цены выбранного типа"},
+		{Text: "p9", Name: "Чужое предложение", Reason: "предложение ссылается на товар, отсутствующий в каталоге"},
+		{Text: "p4", Name: "Крышка без цены", Reason: "для товара нет предложения с ценой"},
+	}
+	if !reflect.DeepEqual(report.Skipped, wantSkipped) {
+		t.Errorf("skipped = %+v", report.Skipped)
+	}
+}
+
+func TestImportCommerceMLChangesOnlyDeletions(t *testing.T) {
+	dir := t.TempDir()
+	importPath := filepath.Join(dir, "import.xml")
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<КоммерческаяИнформация>
+  <Каталог СодержитТолькоИзменения="true">
+    <Товары>
+      <Товар><Ид>p1</Ид><Наименование>Лоток 100х100</Наименование></Товар>
+      <Товар><Ид>p2</Ид><Наименование>Гайка М10</Наименование><ПометкаУдаления>true</ПометкаУдаления></Товар>
+    </Товары>
+  </Каталог>
+  <ПакетПредложений СодержитТолькоИзменения="true">
+    <Предложения>
+      <Предложение><Ид>p1</Ид><Наименование>Лоток 100х100</Наименование><Цены><Цена><ЦенаЗаЕдиницу>470</ЦенаЗаЕдиницу></Цена></Цены></Предложение>
+      <Предложение><Ид>p2#black</Ид><Наименование>Гайка М10 черная</Наименование><Цены><Цена><ЦенаЗаЕдиницу>3.20</ЦенаЗаЕдиницу></Цена></Цены></Предложение>
+    </Предложения>
+  </ПакетПредложений>
+</КоммерческаяИнформация>`
+	if err := os.WriteFile(importPath, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	app := &App{}
+	report := newImportReport(importPath)
+
+	imported, err := app.importCommerceMLCatalog(context.Background(), importPath, report)
+	if err != nil {
+		t.Fatalf("importCommerceMLCatalog: %v", err)
+	}
+	if !report.Partial || !reflect.DeepEqual(report.Deleted, []string{"p2"}) {
+		t.Fatalf("partial = %v, deleted = %v", report.Partial, report.Deleted)
+	}
+	if len(imported) != 1 || imported[0].ExternalID != "p1" {
+		t.Fatalf("предложение удаленного товара не должно импортироваться: %+v", imported)
+	}
+
+	previous := ensureProductKeys([]Product{
+		{ExternalID: "p1", Name: "Лоток 100х100", Price: Money{Kopecks: 45000, Currency: "RUB"}},
+		{ExternalID: "p2#black", Name: "Гайка М10 черная", Price: Money{Kopecks: 320, Currency: "RUB"}},
+		{ExternalID: "p2#white", Name: "Гайка М10 белая", Price: Money{Kopecks: 330, Currency: "RUB"}},
+		{ExternalID: "p5", Name: "Шайба М10", Price: Money{Kopecks: 90, Currency: "RUB"}},
+	})
+	merged := mergePartialImport(previous, ensureProductKeys(imported), report)
+	var names []string
+	for _, p := range merged {
+		names = append(names, fmt.Sprintf("%s=%d", p.ExternalID, p.Price.Kopecks))
+	}
+	if want := []string{"p5=90", "p1=47000"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("после слияния = %v, ожидалось %v", names, want)
+	}
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+var defaultColumnAliases = map[string][]string{
+	"name":     {"наименование", "наименование товара", "название", "товар", "номенклатура", "name", "product"},
+	"article":  {"артикул", "арт.", "арт", "код", "код товара", "sku", "article"},
+	"price":    {"цена", "цена, руб", "цена руб", "цена руб.", "стоимость", "цена с ндс", "розничная цена", "price"},
+	"unit":     {"ед.", "ед", "ед. изм.", "ед.изм.", "единица", "единица измерения", "unit"},
+	"category": {"категория", "группа", "раздел", "category"},
+	"stock":    {"остаток", "остаток на складе", "наличие", "склад", "количество на складе", "stock"},
+	"packSize": {"кратность", "в упаковке", "кол-во в упаковке", "фасовка", "pack"},
+	"vatRate":  {"ставка ндс", "ндс, %", "ндс %", "ндс", "vat"},
+	"cost":     {"себестоимость", "закупочная цена", "цена закупки", "входная цена", "cost"},
+}
+
+type columnMapping struct {
+	Name     int
+	Article  int
+	Price    int
+	Unit     int
+	Category int
+	Stock    int
+	PackSize int
+	VATRate  int
+	Cost     int
+}
+
+func decodeCatalogText(raw []byte, encoding string) (string, string) {
+	raw = bytes.TrimPrefix(raw, []byte("\xef\xbb\xbf"))
+	switch strings.ToLower(strings.ReplaceAll(encoding, "-", "")) {
+	case "utf8":
+		return string(raw), "utf-8"
+	case "cp1251", "windows1251":
+		decoded, _ := charmap.Windows1251.NewDecoder().Bytes(raw)
+		return string(decoded), "windows-1251"
+	}
+	if utf8.Valid(raw) {
+		return string(raw), "utf-8"
+	}
+	decoded, err := charmap.Windows1251.NewDecoder().Bytes(raw)
+	if err != nil {
+		return string(raw), "utf-8"
+	}
+	return string(decoded), "windows-1251"
+}
+
+func detectDelimiter(text string) rune {
+	lines := strings.Split(text, "\n")
+	if len(lines) > 50 {
+		lines = lines[:50]
+	}
+	best, bestScore := ';', 0
+	for _, candidate := range []rune{';', '\t', ',', '|'} {
+		counts := make(map[int]int)
+		for _, line := range lines {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			if n := strings.Count(line, string(candidate)); n > 0 {
+				counts[n]++
+			}
+		}
+		score := 0
+		for _, occurrences := range counts {
+			if occurrences > score {
+				score = occurrences
+			}
+		}
+		if score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+	return best
+}
+
+func parseDelimiter(value string) rune {
+	switch strings.ToLower(value) {
+	case "", "auto":
+		return 0
+	case "tab", "\\t", "\t":
+		return '\t'
+	}
+	r, _ := utf8.DecodeRuneInString(value)
+	return r
+}
+
+func normalizeHeader(value string) string {
+	return strings.Join(strings.Fields(strings.ToLower(strings.TrimSpace(value))), " ")
+}
+
+func resolveColumn(header []string, configured string, aliases []string) int {
+	if configured != "" {
+		if idx, err := strconv.Atoi(configured); err == nil {
+			if idx >= 1 && idx <= len(header) {
+				return idx - 1
+			}
+			return -1
+		}
+		aliases = []string{configured}
+	}
+	for _, alias := range aliases {
+		alias = normalizeHeader(alias)
+		for i, cell := range header {
+			if normalizeHeader(cell) == alias {
+				return i
+			}
+		}
+	}
+	if configured != "" {
+		return -1
+	}
+	for _, alias := range aliases {
+		alias = normalizeHeader(alias)
+		for i, cell := range header {
+			if strings.HasPrefix(normalizeHeader(cell), alias) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func detectColumns(records [][]string, columns CatalogColumns) (columnMapping, int) {
+	numericMapping := columns.Name != "" && columns.Price != ""
+	if numericMapping {
+		_, errName := strconv.Atoi(columns.Name)
+		_, errPrice := strconv.Atoi(columns.Price)
+		numericMapping = errName == nil && errPrice == nil
+	}
+	limit := len(records)
+	if limit > 20 {
+		limit = 20
+	}
+	for i := 0; i < limit; i++ {
+		mapping := columnMapping{
+			Name:     resolveColumn(records[i], columns.Name, defaultColumnAliases["name"]),
+			Article:  resolveColumn(records[i], columns.Article, defaultColumnAliases["article"]),
+			Price:    resolveColumn(records[i], columns.Price, defaultColumnAliases["price"]),
+			Unit:     resolveColumn(records[i], columns.Unit, defaultColumnAliases["unit"]),
+			Category: resolveColumn(records[i], columns.Category, defaultColumnAliases["category"]),
+			Stock:    resolveColumn(records[i], columns.Stock, defaultColumnAliases["stock"]),
+			PackSize: resolveColumn(records[i], columns.PackSize, defaultColumnAliases["packSize"]),
+			VATRate:  resolveColumn(records[i], columns.VATRate, defaultColumnAliases["vatRate"]),
+			Cost:     resolveColumn(records[i], columns.Cost, defaultColumnAliases["cost"]),
+		}
+		if mapping.Name < 0 || mapping.Price < 0 {
+			continue
+		}
+		if numericMapping {
+			return mapping, -1
+		}
+		return mapping, i
+	}
+	return columnMapping{Name: -1, Article: -1, Price: -1, Unit: -1, Category: -1, Stock: -1, PackSize: -1, VATRate: -1, Cost: -1}, -1
+}
+
+func parsePriceValue(value string) (float64, bool) {
+	cleaned := strings.ToLower(strings.TrimSpace(value))
+	for _, suffix := range []string{"руб.", "руб", "р.", "₽", "rub"} {
+		cleaned = strings.ReplaceAll(cleaned, suffix, "")
+	}
+	cleaned = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', ' ', ' ', '\'':
+			return -1
+		}
+		return r
+	}, cleaned)
+	cleaned, ok := normalizeDecimalSeparators(cleaned)
+	if !ok || cleaned == "" {
+		return 0, false
+	}
+	price, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil || price < 0 || math.IsInf(price, 0) || math.IsNaN(price) {
+		return 0, false
+	}
+	return price, true
+}
+
+func normalizeDecimalSeparators(value string) (string, bool) {
+	last := strings.LastIndexAny(value, ".,")
+	if last < 0 {
+		return value, true
+	}
+	decimal := value[last : last+1]
+	grouping := ","
+	if decimal == "," {
+		grouping = "."
+	}
+	if strings.Contains(value, grouping) {
+		if strings.Count(value, decimal) > 1 || !validDigitGrouping(value[:last], grouping) {
+			return "", false
+		}
+		return strings.ReplaceAll(value[:last], grouping, "") + "." + value[last+1:], true
+	}
+	if strings.Count(value, decimal) == 1 {
+		return strings.Replace(value, decimal, ".", 1), true
+	}
+	if !validDigitGrouping(value, decimal) {
+		return "", false
+	}
+	return strings.ReplaceAll(value, decimal, ""), true
+}
+
+func validDigitGrouping(value, separator string) bool {
+	groups := strings.Split(strings.TrimPrefix(value, "-"), separator)
+	if len(groups[0]) == 0 || len(groups[0]) > 3 {
+		return false
+	}
+	for _, group := range groups[1:] {
+		if len(group) != 3 {
+			return false
+		}
+	}
+	return true
+}
+
+func cellAt(record []string, idx int) string {
+	if idx < 0 || idx >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[idx])
+}
+
+func countNonEmptyCells(record []string) int {
+	count := 0
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			count++
+		}
+	}
+	return count
+}
+
+func readCSVRecords(text string, delimiter rune) ([][]string, []int, error) {
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.Comma = delimiter
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	var records [][]string
+	var lines []int
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, record)
+		lines = append(lines, line)
+	}
+	return records, lines, nil
+}
+
+func (a *App) importCSVCatalog(ctx context.Context, path string, report *ImportReport) ([]Product, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать '%s': %w", path, err)
+	}
+	cfg := a.config.Catalog
+	text, encoding := decodeCatalogText(raw, cfg.Encoding)
+	delimiter := parseDelimiter(cfg.Delimiter)
+	if delimiter == 0 {
+		delimiter = detectDelimiter(text)
+	}
+	log.Printf("Импорт '%s': кодировка %s, разделитель %q.", path, encoding, delimiter)
+
+	records, lines, err := readCSVRecords(text, delimiter)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения CSV '%s': %w", path, err)
+	}
+	mapping, headerRow := detectColumns(records, cfg.Columns)
+	if mapping.Name < 0 || mapping.Price < 0 {
+		if !cfg.LLMFallback {
+			return nil, fmt.Errorf("в '%s' не найдены колонки с названием и ценой; задайте catalog.columns в config.json", path)
+		}
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: в '%s' не найдены колонки с названием и ценой, файл будет разобран через LLM.", path)
+		return a.normalizeCatalogWithLLM(ctx, splitSourceLines(text), report)
+	}
+
+	table := catalogTable{source: path, records: records, lines: lines, separator: string(delimiter)}
+	return a.productsFromTable(ctx, table, mapping, headerRow, report)
+}
@@ -0,0 +1,84 @@
+package main
+
+import "testing"
+
+func TestParsePriceValue(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   float64
+		wantOK bool
+	}{
+		{"1234", 1234, true},
+		{"12,5", 12.5, true},
+		{"12.5", 12.5, true},
+		{"1 234,56 руб.", 1234.56, true},
+		{"1 234,56 ₽", 1234.56, true},
+		{"1.234,56", 1234.56, true},
+		{"1,234.56", 1234.56, true},
+		{"1.234.567,89", 1234567.89, true},
+		{"1,234,567", 1234567, true},
+		{"1.234.567", 1234567, true},
+		{"1'234.50", 1234.5, true},
+		{"1,2.34", 0, false},
+		{"1.234,56,7", 0, false},
+		{"1,23,456", 0, false},
+		{"-5", 0, false},
+		{"", 0, false},
+		{"договорная", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parsePriceValue(tt.value)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("parsePriceValue(%q) = %v, %v; ожидалось %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestDetectColumns(t *testing.T) {
+	tests := []struct {
+		name       string
+		records    [][]string
+		columns    CatalogColumns
+		want       columnMapping
+		wantHeader int
+	}{
+		{
+			name: "заголовок после шапки",
+			records: [][]string{
+				{"Прайс-лист ООО Ромашка"},
+				{"Артикул", "Наименование товара", "Ед. изм.", "Цена, руб", "Остаток"},
+				{"LP-100", "Лоток", "м", "450,50", "12"},
+			},
+			want:       columnMapping{Name: 1, Article: 0, Price: 3, Unit: 2, Category: -1, Stock: 4, PackSize: -1, VATRate: -1, Cost: -1},
+			wantHeader: 1,
+		},
+		{
+			name:       "колонки из конфигурации по имени",
+			records:    [][]string{{"Позиция", "Стоимость опт", "Цена"}},
+			columns:    CatalogColumns{Name: "Позиция", Price: "Стоимость опт"},
+			want:       columnMapping{Name: 0, Article: -1, Price: 1, Unit: -1, Category: -1, Stock: -1, PackSize: -1, VATRate: -1, Cost: -1},
+			wantHeader: 0,
+		},
+		{
+			name:       "номера колонок без заголовка",
+			records:    [][]string{{"LP-100", "Лоток", "450,50"}},
+			columns:    CatalogColumns{Name: "2", Price: "3", Article: "1"},
+			want:       columnMapping{Name: 1, Article: 0, Price: 2, Unit: -1, Category: -1, Stock: -1, PackSize: -1, VATRate: -1, Cost: -1},
+			wantHeader: -1,
+		},
+		{
+			name:       "колонки не найдены",
+			records:    [][]string{{"a", "b"}, {"1", "2"}},
+			want:       columnMapping{Name: -1, Article: -1, Price: -1, Unit: -1, Category: -1, Stock: -1, PackSize: -1, VATRate: -1, Cost: -1},
+			wantHeader: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, header := detectColumns(tt.records, tt.columns)
+			if got != tt.want || header != tt.wantHeader {
+				t.Errorf("detectColumns = %+v, %d; ожидалось %+v, %d", got, header, tt.want, tt.wantHeader)
+			}
+		})
+	}
+}
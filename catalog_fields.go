@@ -0,0 +1,85 @@
+package main
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+var unitAliases = map[string]string{
+	"шт": "шт", "штук": "шт", "штука": "шт", "штуки": "шт", "pcs": "шт", "796": "шт",
+	"м": "м", "метр": "м", "метров": "м", "пог. м": "м", "пог.м": "м", "п.м": "м", "мп": "м", "006": "м",
+	"кг": "кг", "килограмм": "кг", "166": "кг",
+	"упак": "упак", "уп": "упак", "упаковка": "упак", "упаковок": "упак", "778": "упак",
+	"компл": "компл", "комплект": "компл", "кмп": "компл", "839": "компл",
+}
+
+func normalizeUnit(value string) string {
+	value = strings.TrimSpace(value)
+	key := strings.TrimSuffix(strings.ToLower(value), ".")
+	if unit, ok := unitAliases[key]; ok {
+		return unit
+	}
+	return value
+}
+
+func parseNumberCell(value string) (float64, bool) {
+	cleaned := strings.TrimLeft(strings.TrimSpace(value), "<>≥≤=~ ")
+	end := 0
+	for i, r := range cleaned {
+		if (r >= '0' && r <= '9') || r == ',' || r == '.' || r == ' ' || r == '\u00a0' {
+			end = i + len(string(r))
+			continue
+		}
+		break
+	}
+	return parsePriceValue(cleaned[:end])
+}
+
+func parseStockValue(value string) *float64 {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return nil
+	}
+	switch value {
+	case "нет", "нет в наличии", "под заказ", "-":
+		zero := 0.0
+		return &zero
+	}
+	if stock, ok := parseNumberCell(value); ok {
+		return &stock
+	}
+	return nil
+}
+
+func parsePackSize(value string) float64 {
+	if size, ok := parseNumberCell(value); ok && size > 0 {
+		return size
+	}
+	return 0
+}
+
+func parseVATRate(value string) *int {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return nil
+	}
+	if strings.Contains(value, "без") {
+		zero := 0
+		return &zero
+	}
+	value = strings.TrimSpace(strings.TrimPrefix(value, "ндс"))
+	rate, ok := parseNumberCell(value)
+	if !ok {
+		return nil
+	}
+	if rate > 0 && rate < 1 {
+		rate *= 100
+	}
+	percent := int(math.Round(rate))
+	return &percent
+}
+
+func formatQuantity(value float64) string {
+	return strings.Replace(strconv.FormatFloat(value, 'f', -1, 64), ".", ",", 1)
+}
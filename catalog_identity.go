@@ -0,0 +1,205 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+const catalogIDsFile = "catalog_ids.json"
+
+type PriceChange struct {
+	ID       int    `json:"id"`
+	Key      string `json:"key"`
+	Name     string `json:"name"`
+	OldPrice Money  `json:"oldPrice"`
+	NewPrice Money  `json:"newPrice"`
+}
+
+type CatalogDiff struct {
+	Added     []Product     `json:"added"`
+	Removed   []Product     `json:"removed"`
+	Repriced  []PriceChange `json:"repriced"`
+	Unchanged int           `json:"unchanged"`
+}
+
+type catalogIDRegistry struct {
+	NextID int            `json:"nextId"`
+	IDs    map[string]int `json:"ids"`
+	Names  map[string]int `json:"names,omitempty"`
+}
+
+func normalizeIdentityText(value string) string {
+	value = strings.ReplaceAll(strings.ToLower(value), "ё", "е")
+	return strings.Join(strings.Fields(value), " ")
+}
+
+func productKey(p Product) string {
+	if p.ExternalID != "" {
+		return "1c:" + p.ExternalID
+	}
+	if article := strings.Join(strings.Fields(strings.ToLower(p.Article)), ""); article != "" {
+		return "art:" + article
+	}
+	return "hash:" + identityHash(p)
+}
+
+func identityHash(p Product) string {
+	sum := sha1.Sum([]byte(normalizeIdentityText(p.Name) + "|" + normalizeIdentityText(normalizeUnit(p.Unit))))
+	return hex.EncodeToString(sum[:8])
+}
+
+func ensureProductKeys(products []Product) []Product {
+	counts := make(map[string]int, len(products))
+	for i := range products {
+		if products[i].Key == "" {
+			products[i].Key = productKey(products[i])
+		}
+		counts[products[i].Key]++
+	}
+	used := make(map[string]int, len(products))
+	for i, p := range products {
+		key := p.Key
+		if counts[key] > 1 && !strings.HasPrefix(key, "hash:") {
+			key += "#" + identityHash(p)
+		}
+		used[key]++
+		if n := used[key]; n > 1 {
+			key = fmt.Sprintf("%s#%d", key, n)
+		}
+		products[i].Key = key
+	}
+	return products
+}
+
+func loadCatalogIDRegistry(current []Product) (*catalogIDRegistry, error) {
+	registry := newCatalogIDRegistry()
+	data, err := os.ReadFile(catalogIDsFile)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, registry); err != nil {
+			return nil, fmt.Errorf("файл '%s' поврежден: %w", catalogIDsFile, err)
+		}
+		if registry.IDs == nil {
+			registry.IDs = make(map[string]int)
+		}
+		if registry.Names == nil {
+			registry.Names = make(map[string]int)
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, fmt.Errorf("не удалось прочитать '%s': %w", catalogIDsFile, err)
+	}
+	for _, p := range current {
+		if p.Key == "" || p.ID <= 0 {
+			continue
+		}
+		if _, ok := registry.IDs[p.Key]; !ok {
+			registry.IDs[p.Key] = p.ID
+		}
+		if _, ok := registry.Names[identityHash(p)]; !ok {
+			registry.Names[identityHash(p)] = p.ID
+		}
+	}
+	for _, id := range registry.IDs {
+		if id >= registry.NextID {
+			registry.NextID = id + 1
+		}
+	}
+	return registry, nil
+}
+
+func newCatalogIDRegistry() *catalogIDRegistry {
+	return &catalogIDRegistry{NextID: 1, IDs: make(map[string]int), Names: make(map[string]int)}
+}
+
+func (r *catalogIDRegistry) assign(products []Product) []Product {
+	products = ensureProductKeys(products)
+	claimed := make(map[int]bool, len(products))
+	for _, p := range products {
+		if id, ok := r.lookup(p); ok {
+			claimed[id] = true
+		}
+	}
+	for i, p := range products {
+		if id, ok := r.lookup(p); ok {
+			r.IDs[p.Key] = id
+			products[i].ID = id
+			continue
+		}
+		id, ok := r.Names[identityHash(p)]
+		if !ok || claimed[id] {
+			id = r.NextID
+			r.NextID++
+		}
+		r.IDs[p.Key] = id
+		products[i].ID = id
+		claimed[id] = true
+	}
+	for _, p := range products {
+		r.Names[identityHash(p)] = p.ID
+	}
+	return products
+}
+
+func (r *catalogIDRegistry) lookup(p Product) (int, bool) {
+	if !strings.HasPrefix(p.Key, "hash:") && !strings.Contains(p.Key, "#") {
+		if id, ok := r.IDs[p.Key+"#"+identityHash(p)]; ok {
+			return id, true
+		}
+	}
+	id, ok := r.IDs[p.Key]
+	return id, ok
+}
+
+func (r *catalogIDRegistry) save() error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("не удалось сформировать JSON реестра идентификаторов: %w", err)
+	}
+	if err := os.WriteFile(catalogIDsFile, data, 0644); err != nil {
+		return fmt.Errorf("не удалось записать '%s': %w", catalogIDsFile, err)
+	}
+	return nil
+}
+
+func diffCatalogs(previous, current []Product) CatalogDiff {
+	diff := CatalogDiff{Added: []Product{}, Removed: []Product{}, Repriced: []PriceChange{}}
+	old := make(map[int]Product, len(previous))
+	for _, p := range previous {
+		old[p.ID] = p
+	}
+	seen := make(map[int]bool, len(current))
+	for _, p := range current {
+		seen[p.ID] = true
+		before, ok := old[p.ID]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, p)
+		case before.Price != p.Price:
+			diff.Repriced = append(diff.Repriced, PriceChange{ID: p.ID, Key: p.Key, Name: p.Name, OldPrice: before.Price, NewPrice: p.Price})
+		default:
+			diff.Unchanged++
+		}
+	}
+	for _, p := range previous {
+		if !seen[p.ID] {
+			diff.Removed = append(diff.Removed, p)
+		}
+	}
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].ID < diff.Removed[j].ID })
+	return diff
+}
+
+func (d CatalogDiff) logSummary() {
+	log.Printf("Изменения каталога: добавлено %d, удалено %d, изменена цена %d, без изменений %d.", len(d.Added), len(d.Removed), len(d.Repriced), d.Unchanged)
+	for _, change := range d.Repriced {
+		log.Printf("  цена #%d '%s': %s -> %s", change.ID, change.Name, change.OldPrice, change.NewPrice)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func identityCatalog() []Product {
+	return []Product{
+		{Name: "Лоток перфорированный 100х100", Article: "LP-100", Unit: "м", Price: Money{Kopecks: 45050, Currency: "RUB"}},
+		{Name: "Гайка М10", Article: "G-M10", Unit: "шт", Price: Money{Kopecks: 320, Currency: "RUB"}},
+		{Name: "Гайка М10 оцинкованная", Article: "G-M10", Unit: "шт", Price: Money{Kopecks: 410, Currency: "RUB"}},
+		{Name: "Крышка для лотка", Unit: "м", Price: Money{Kopecks: 21000, Currency: "RUB"}},
+		{Name: "Крышка для лотка", Unit: "м", Price: Money{Kopecks: 21000, Currency: "RUB"}},
+	}
+}
+
+func idsByName(products []Product) map[string]map[int]bool {
+	ids := make(map[string]map[int]bool, len(products))
+	for _, p := range products {
+		name := p.Name + "|" + p.Price.String()
+		if ids[name] == nil {
+			ids[name] = make(map[int]bool)
+		}
+		ids[name][p.ID] = true
+	}
+	return ids
+}
+
+func TestEnsureProductKeys(t *testing.T) {
+	products := ensureProductKeys(identityCatalog())
+	seen := make(map[string]bool)
+	for _, p := range products {
+		if seen[p.Key] {
+			t.Fatalf("ключ %q повторяется", p.Key)
+		}
+		seen[p.Key] = true
+	}
+	if products[0].Key != "art:lp-100" {
+		t.Errorf("уникальный артикул должен оставаться ключом без суффикса: %q", products[0].Key)
+	}
+	if !strings.HasPrefix(products[1].Key, "art:g-m10#") || !strings.HasPrefix(products[2].Key, "art:g-m10#") {
+		t.Errorf("совпадающие артикулы должны различаться суффиксом: %q, %q", products[1].Key, products[2].Key)
+	}
+	if products[4].Key != products[3].Key+"#2" {
+		t.Errorf("полные дубли должны нумероваться: %q, %q", products[3].Key, products[4].Key)
+	}
+
+	reordered := identityCatalog()
+	reordered[1], reordered[2] = reordered[2], reordered[1]
+	reordered = ensureProductKeys(reordered)
+	if reordered[1].Key != products[2].Key || reordered[2].Key != products[1].Key {
+		t.Errorf("ключи зависят от порядка строк: %q, %q", reordered[1].Key, reordered[2].Key)
+	}
+}
+
+func TestCatalogIDRegistryAssignStable(t *testing.T) {
+	registry := newCatalogIDRegistry()
+	first := registry.assign(identityCatalog())
+	want := idsByName(first)
+
+	tests := []struct {
+		name   string
+		change func([]Product) []Product
+	}{
+		{"обратный порядок строк", func(products []Product) []Product {
+			for i, j := 0, len(products)-1; i < j; i, j = i+1, j-1 {
+				products[i], products[j] = products[j], products[i]
+			}
+			return products
+		}},
+		{"переставлены товары с одним артикулом", func(products []Product) []Product {
+			products[1], products[2] = products[2], products[1]
+			return products
+		}},
+		{"изменены цены", func(products []Product) []Product {
+			for i := range products {
+				products[i].Price = products[i].Price.Mul(2)
+			}
+			return products
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			products := registry.assign(tt.change(identityCatalog()))
+			for _, p := range products {
+				original := p
+				if tt.name == "изменены цены" {
+					original.Price.Kopecks /= 2
+				}
+				if ids := want[original.Name+"|"+original.Price.String()]; !ids[p.ID] {
+					t.Errorf("'%s': ID %d, ожидался один из %v", p.Name, p.ID, ids)
+				}
+			}
+		})
+	}
+
+	renamed := identityCatalog()
+	renamed[0].Name = "Лоток перфорированный 100х100 (новое название)"
+	renamed = registry.assign(renamed)
+	if renamed[0].ID != first[0].ID {
+		t.Errorf("переименование товара с артикулом сменило ID: %d -> %d", first[0].ID, renamed[0].ID)
+	}
+	if registry.NextID != 6 {
+		t.Errorf("перестановки и переоценка не должны выдавать новые ID, NextID = %d", registry.NextID)
+	}
+}
+
+func TestDiffCatalogs(t *testing.T) {
+	registry := newCatalogIDRegistry()
+	previous := registry.assign(identityCatalog())
+
+	current := identityCatalog()
+	current[0], current[3] = current[3], current[0]
+	current[3].Name = "Лоток перфорированный 100х100 усиленный"
+	current[3].Price = Money{Kopecks: 47000, Currency: "RUB"}
+	current = append(current[:1], current[2:]...)
+	current = append(current, Product{Name: "Шайба М10", Article: "SH-M10", Unit: "шт", Price: Money{Kopecks: 90, Currency: "RUB"}})
+	current = registry.assign(current)
+
+	diff := diffCatalogs(previous, current)
+	if len(diff.Added) != 1 || diff.Added[0].Name != "Шайба М10" {
+		t.Errorf("добавлено = %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Name != "Гайка М10" {
+		t.Errorf("удалено = %+v", diff.Removed)
+	}
+	if len(diff.Repriced) != 1 || diff.Repriced[0].ID != previous[0].ID || diff.Repriced[0].OldPrice.Kopecks != 45050 || diff.Repriced[0].NewPrice.Kopecks != 47000 {
+		t.Errorf("изменена цена = %+v", diff.Repriced)
+	}
+	if diff.Unchanged != 3 {
+		t.Errorf("без изменений = %d", diff.Unchanged)
+	}
+}
+
+func TestCatalogIDRegistryArticleCollisions(t *testing.T) {
+	registry := newCatalogIDRegistry()
+	single := registry.assign([]Product{{Name: "Гайка М10", Article: "G-M10", Unit: "шт"}})
+
+	grown := registry.assign([]Product{
+		{Name: "Гайка М10 оцинкованная", Article: "G-M10", Unit: "шт"},
+		{Name: "Гайка М10", Article: "G-M10", Unit: "шт"},
+	})
+	if grown[1].ID != single[0].ID || grown[0].ID == single[0].ID {
+		t.Fatalf("после появления второго товара с тем же артикулом ID = %d, %d; ожидался %d у исходного", grown[0].ID, grown[1].ID, single[0].ID)
+	}
+
+	shrunk := registry.assign([]Product{{Name: "Гайка М10 оцинкованная", Article: "G-M10", Unit: "шт"}})
+	if shrunk[0].ID != grown[0].ID {
+		t.Errorf("после удаления дубля ID = %d, ожидался %d", shrunk[0].ID, grown[0].ID)
+	}
+}
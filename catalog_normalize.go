@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultNormalizeChunkLines = 80
+	defaultNormalizeWorkers    = 3
+)
+
+type sourceLine struct {
+	Line int
+	Text string
+}
+
+type normalizedRow struct {
+	Line    int      `json:"line"`
+	Name    string   `json:"name"`
+	Article string   `json:"article"`
+	Unit    string   `json:"unit"`
+	Price   *float64 `json:"price"`
+	Skip    bool     `json:"skip"`
+}
+
+type normalizeChunkResult struct {
+	products []Product
+	skipped  []ImportIssue
+	ignored  []ImportIssue
+	invented []ImportIssue
+}
+
+func splitSourceLines(text string) []sourceLine {
+	var lines []sourceLine
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		lines = append(lines, sourceLine{Line: i + 1, Text: line})
+	}
+	return lines
+}
+
+func chunkSourceLines(lines []sourceLine, size int) [][]sourceLine {
+	var chunks [][]sourceLine
+	for start := 0; start < len(lines); start += size {
+		end := start + size
+		if end > len(lines) {
+			end = len(lines)
+		}
+		chunks = append(chunks, lines[start:end])
+	}
+	return chunks
+}
+
+func (a *App) normalizeCatalogWithLLM(ctx context.Context, lines []sourceLine, report *ImportReport) ([]Product, error) {
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	chunkSize := a.config.Catalog.LLMChunkLines
+	if chunkSize <= 0 {
+		chunkSize = defaultNormalizeChunkLines
+	}
+	workers := a.config.Catalog.LLMWorkers
+	if workers <= 0 {
+		workers = defaultNormalizeWorkers
+	}
+	chunks := chunkSourceLines(lines, chunkSize)
+	log.Printf("Нормализация через LLM: %d строк, %d фрагментов, %d потоков.", len(lines), len(chunks), workers)
+
+	results := make([]normalizeChunkResult, len(chunks))
+	semaphore := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk []sourceLine) {
+			defer wg.Done()
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-semaphore }()
+			results[i] = a.normalizeChunk(ctx, chunk)
+			log.Printf("Фрагмент %d/%d нормализован: товаров %d, пропущено %d, отброшено %d.", i+1, len(chunks), len(results[i].products), len(results[i].skipped), len(results[i].invented))
+		}(i, chunk)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var products []Product
+	for _, result := range results {
+		products = append(products, result.products...)
+		report.Skipped = append(report.Skipped, result.skipped...)
+		report.Ignored = append(report.Ignored, result.ignored...)
+		report.Invented = append(report.Invented, result.invented...)
+	}
+	return products, nil
+}
+
+func (a *App) normalizeChunk(ctx context.Context, chunk []sourceLine) normalizeChunkResult {
+	prompt := `Ты — сверхточный ассистент по извлечению данных из прайс-листа. Каждая строка ниже начинается с номера строки исходного файла и двоеточия. Для КАЖДОЙ строки верни ровно один объект JSON-массива.
+
+Правила:
+- Если в строке есть товар с ценой, верни {"line": номер, "name": "название товара", "price": цена}.
+- Если в строке явно указаны артикул или единица измерения (шт, м, кг, упак), добавь поля "article" и "unit". Если их нет — не добавляй.
+- Цену извлекай как число, убирая "руб." и другие символы. Копейки обязательно сохраняй дробной частью через точку (12.50), не округляй.
+- Название товара бери из строки как есть, ничего не придумывай и не дополняй.
+- Если в строке нет товара или цены (заголовок, раздел, комментарий), верни {"line": номер, "skip": true}.
+- Номер строки копируй без изменений. Не добавляй строк, которых нет во входных данных.
+- Не добавляй никаких комментариев или текста до и после JSON. Вывод должен быть только валидным JSON-массивом.
+
+Строки для обработки:
+---
+%s
+---`
+	var numbered strings.Builder
+	for _, line := range chunk {
+		fmt.Fprintf(&numbered, "%d: %s\n", line.Line, line.Text)
+	}
+	fullPrompt := fmt.Sprintf(prompt, numbered.String())
+
+	var result normalizeChunkResult
+	completion, err := a.callLLMForJSON(ctx, StageCatalogParsing, fullPrompt, ChatOptions{Temperature: 0})
+	var rows []normalizedRow
+	if err == nil {
+		if jsonErr := json.Unmarshal([]byte(completion.Content), &rows); jsonErr != nil {
+			err = fmt.Errorf("LLM вернула невалидный JSON: %w", jsonErr)
+		}
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("ПРЕДУПРЕЖДЕНИЕ: фрагмент со строки %d не нормализован: %v", chunk[0].Line, err)
+		}
+		for _, line := range chunk {
+			result.skipped = append(result.skipped, ImportIssue{Line: line.Line, Text: line.Text, Reason: fmt.Sprintf("ошибка LLM: %v", err)})
+		}
+		return result
+	}
+	return reconcileNormalizedRows(chunk, rows, a.config.Catalog.Currency)
+}
+
+func reconcileNormalizedRows(chunk []sourceLine, rows []normalizedRow, currency string) normalizeChunkResult {
+	var result normalizeChunkResult
+	byLine := make(map[int]sourceLine, len(chunk))
+	for _, line := range chunk {
+		byLine[line.Line] = line
+	}
+	seen := make(map[int]bool, len(chunk))
+	for _, row := range rows {
+		source, exists := byLine[row.Line]
+		issue := ImportIssue{Line: row.Line, Text: source.Text, Name: row.Name}
+		if row.Price != nil {
+			issue.Price = *row.Price
+		}
+		switch {
+		case !exists:
+			issue.Reason = "строка отсутствует во входном фрагменте"
+			result.invented = append(result.invented, issue)
+			continue
+		case seen[row.Line]:
+			issue.Reason = "повторный ответ для строки"
+			result.invented = append(result.invented, issue)
+			continue
+		}
+		seen[row.Line] = true
+		if row.Skip || (row.Name == "" && row.Price == nil) {
+			issue.Reason = "в строке нет товара"
+			result.ignored = append(result.ignored, issue)
+			continue
+		}
+		name := strings.TrimSpace(row.Name)
+		if name == "" || row.Price == nil || *row.Price < 0 {
+			issue.Reason = "LLM не вернула название или цену"
+			result.skipped = append(result.skipped, issue)
+			continue
+		}
+		if !nameMatchesSource(name, source.Text) {
+			issue.Reason = "название не найдено в исходной строке"
+			result.invented = append(result.invented, issue)
+			continue
+		}
+		article := strings.TrimSpace(row.Article)
+		if !strings.Contains(strings.ToLower(source.Text), strings.ToLower(article)) {
+			article = ""
+		}
+		result.products = append(result.products, Product{
+			Name:       name,
+			Article:    article,
+			Unit:       normalizeUnit(row.Unit),
+			Price:      moneyFromFloat(*row.Price, currency),
+			SourceLine: row.Line,
+		})
+	}
+	for _, line := range chunk {
+		if !seen[line.Line] {
+			result.skipped = append(result.skipped, ImportIssue{Line: line.Line, Text: line.Text, Reason: "строка пропущена LLM"})
+		}
+	}
+	return result
+}
+
+func nameMatchesSource(name, source string) bool {
+	sourceLower := strings.ToLower(source)
+	words := tokenize(name)
+	if len(words) == 0 {
+		return false
+	}
+	matched := 0
+	for _, word := range words {
+		if strings.Contains(sourceLower, word) {
+			matched++
+		}
+	}
+	return matched*2 >= len(words)
+}
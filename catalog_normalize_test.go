@@ -0,0 +1,62 @@
+package main
+
+import "testing"
+
+func TestReconcileNormalizedRows(t *testing.T) {
+	price := func(v float64) *float64 { return &v }
+	chunk := []sourceLine{
+		{Line: 1, Text: "ПРАЙС-ЛИСТ на 01.10"},
+		{Line: 2, Text: "Лоток перфорированный 100х100 арт. LP-100 — 450,50 р/м"},
+		{Line: 3, Text: "Гайка М10 оцинк. 3.20"},
+		{Line: 4, Text: "Крышка 200 мм"},
+		{Line: 5, Text: "Шайба М8"},
+	}
+	rows := []normalizedRow{
+		{Line: 1, Skip: true},
+		{Line: 2, Name: "Лоток перфорированный 100х100", Article: "LP-100", Unit: "м.", Price: price(450.5)},
+		{Line: 3, Name: "Гайка М10", Article: "G-10", Unit: "шт", Price: price(3.2)},
+		{Line: 3, Name: "Гайка М10", Price: price(3.2)},
+		{Line: 4, Name: "Крышка 200 мм"},
+		{Line: 9, Name: "Болт М12", Price: price(15)},
+		{Line: 5, Name: "Кронштейн настенный", Price: price(40)},
+	}
+
+	result := reconcileNormalizedRows(chunk, rows, "RUB")
+
+	if len(result.products) != 2 {
+		t.Fatalf("ожидалось 2 товара, получено %+v", result.products)
+	}
+	lp := result.products[0]
+	if lp.Name != "Лоток перфорированный 100х100" || lp.Article != "LP-100" || lp.Unit != "м" || lp.Price.Kopecks != 45050 || lp.SourceLine != 2 {
+		t.Errorf("товар строки 2 = %+v", lp)
+	}
+	if nut := result.products[1]; nut.Article != "" || nut.Price.Kopecks != 320 || nut.SourceLine != 3 {
+		t.Errorf("выдуманный артикул должен быть отброшен: %+v", nut)
+	}
+
+	reasons := func(issues []ImportIssue) map[int]string {
+		m := make(map[int]string)
+		for _, issue := range issues {
+			m[issue.Line] = issue.Reason
+		}
+		return m
+	}
+	if got := reasons(result.ignored); len(got) != 1 || got[1] != "в строке нет товара" {
+		t.Errorf("ignored = %v", got)
+	}
+	if got := reasons(result.skipped); len(got) != 1 || got[4] != "LLM не вернула название или цену" {
+		t.Errorf("skipped = %v", got)
+	}
+	invented := reasons(result.invented)
+	if len(result.invented) != 3 || invented[3] != "повторный ответ для строки" || invented[9] != "строка отсутствует во входном фрагменте" || invented[5] != "название не найдено в исходной строке" {
+		t.Errorf("invented = %+v", result.invented)
+	}
+}
+
+func TestReconcileNormalizedRowsReportsMissingLines(t *testing.T) {
+	chunk := []sourceLine{{Line: 10, Text: "Лоток 50х50 120,00"}, {Line: 11, Text: "Лоток 100х50 150,00"}}
+	result := reconcileNormalizedRows(chunk, nil, "RUB")
+	if len(result.products) != 0 || len(result.skipped) != 2 || result.skipped[0].Reason != "строка пропущена LLM" {
+		t.Errorf("result = %+v", result)
+	}
+}
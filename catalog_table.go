@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strings"
+)
+
+type catalogTable struct {
+	source       string
+	records      [][]string
+	lines        []int
+	separator    string
+	categoryRows map[int]bool
+}
+
+func firstNonEmptyCell(record []string) string {
+	for _, cell := range record {
+		if cell = strings.TrimSpace(cell); cell != "" {
+			return cell
+		}
+	}
+	return ""
+}
+
+func (a *App) productsFromTable(ctx context.Context, table catalogTable, mapping columnMapping, headerRow int, report *ImportReport) ([]Product, error) {
+	var products []Product
+	var failed []sourceLine
+	category := ""
+	for i := headerRow + 1; i < len(table.records); i++ {
+		record := table.records[i]
+		name := cellAt(record, mapping.Name)
+		priceCell := cellAt(record, mapping.Price)
+		if countNonEmptyCells(record) == 0 {
+			continue
+		}
+		if table.categoryRows[i] || (priceCell == "" && countNonEmptyCells(record) == 1) {
+			category = firstNonEmptyCell(record)
+			continue
+		}
+		price, ok := parsePriceValue(priceCell)
+		if name == "" || !ok {
+			failed = append(failed, sourceLine{Line: table.lines[i], Text: strings.Join(record, table.separator)})
+			continue
+		}
+		product := Product{
+			Name:       name,
+			Article:    cellAt(record, mapping.Article),
+			Unit:       normalizeUnit(cellAt(record, mapping.Unit)),
+			Category:   category,
+			Price:      moneyFromFloat(price, a.config.Catalog.Currency),
+			Stock:      parseStockValue(cellAt(record, mapping.Stock)),
+			PackSize:   parsePackSize(cellAt(record, mapping.PackSize)),
+			VATRate:    parseVATRate(cellAt(record, mapping.VATRate)),
+			SourceLine: table.lines[i],
+		}
+		if cost, ok := parsePriceValue(cellAt(record, mapping.Cost)); ok {
+			costMoney := moneyFromFloat(cost, a.config.Catalog.Currency)
+			product.Cost = &costMoney
+		}
+		if rowCategory := cellAt(record, mapping.Category); rowCategory != "" {
+			product.Category = rowCategory
+		}
+		products = append(products, product)
+	}
+	log.Printf("Импорт '%s': разобрано %d строк, не удалось разобрать %d.", table.source, len(products), len(failed))
+
+	if len(failed) == 0 {
+		return products, nil
+	}
+	for _, row := range failed {
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: строка %d не разобрана: %s", row.Line, row.Text)
+	}
+	if !a.config.Catalog.LLMFallback {
+		for _, row := range failed {
+			report.addSkipped(row, "не удалось разобрать строку")
+		}
+		return products, nil
+	}
+	recovered, err := a.normalizeCatalogWithLLM(ctx, failed, report)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("LLM восстановила %d из %d неразобранных строк.", len(recovered), len(failed))
+	return append(products, recovered...), nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func chdirTemp(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	return dir
+}
+
+func TestReimportCatalogDeletionsOnly(t *testing.T) {
+	dir := chdirTemp(t)
+	source := filepath.Join(dir, "import.xml")
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<КоммерческаяИнформация>
+  <Каталог СодержитТолькоИзменения="true">
+    <Товары>
+      <Товар><Ид>p2</Ид><Наименование>Гайка М10</Наименование><ПометкаУдаления>true</ПометкаУдаления></Товар>
+    </Товары>
+  </Каталог>
+</КоммерческаяИнформация>`
+	if err := os.WriteFile(source, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	app := &App{config: Config{Catalog: CatalogConfig{Source: source}}}
+	app.setCatalog(ensureProductKeys([]Product{
+		{ID: 1, ExternalID: "p1", Name: "Лоток 100х100", Price: Money{Kopecks: 45000, Currency: "RUB"}},
+		{ID: 2, ExternalID: "p2", Name: "Гайка М10", Price: Money{Kopecks: 320, Currency: "RUB"}},
+	}))
+
+	diff, err := app.reimportCatalogLocked(context.Background())
+	if err != nil {
+		t.Fatalf("пакет только с удалениями должен применяться: %v", err)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].ExternalID != "p2" || diff.Unchanged != 1 {
+		t.Errorf("diff = %+v", diff)
+	}
+	products := app.currentCatalog().products
+	if len(products) != 1 || products[0].ID != 1 {
+		t.Errorf("после импорта = %+v", products)
+	}
+}
+
+func TestMergePartialImportOffersOnly(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "offers.xml")
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<КоммерческаяИнформация>
+  <ПакетПредложений СодержитТолькоИзменения="true">
+    <Предложения>
+      <Предложение><Ид>p1</Ид><Наименование>лоток 100x100 (прайс)</Наименование><Цены><Цена><ЦенаЗаЕдиницу>470</ЦенаЗаЕдиницу></Цена></Цены><Количество>7</Количество></Предложение>
+      <Предложение><Ид>p2</Ид><Наименование>Гайка М10</Наименование><Цены><Цена><ЦенаЗаЕдиницу>3,50</ЦенаЗаЕдиницу></Цена></Цены></Предложение>
+      <Предложение><Ид>p9</Ид><Наименование>Шайба М10</Наименование><Цены><Цена><ЦенаЗаЕдиницу>0,90</ЦенаЗаЕдиницу></Цена></Цены></Предложение>
+    </Предложения>
+  </ПакетПредложений>
+</КоммерческаяИнформация>`
+	if err := os.WriteFile(source, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	report := newImportReport(source)
+	imported, err := (&App{}).importCommerceMLCatalog(context.Background(), source, report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Partial || !reflect.DeepEqual(report.PriceOnly, []string{"p1", "p2", "p9"}) {
+		t.Fatalf("partial = %v, priceOnly = %v", report.Partial, report.PriceOnly)
+	}
+
+	vat := 20
+	stock := 3.0
+	previous := ensureProductKeys([]Product{
+		{ID: 1, ExternalID: "p1", Name: "Лоток 100х100", Article: "LP-100", Unit: "м", Category: "Лотки", VATRate: &vat, Stock: &stock, Price: Money{Kopecks: 45000, Currency: "RUB"}},
+		{ID: 2, ExternalID: "p2", Name: "Гайка М10", Article: "G-M10", Unit: "шт", Category: "Метизы", Stock: &stock, PackSize: 100, Price: Money{Kopecks: 320, Currency: "RUB"}},
+	})
+	merged := mergePartialImport(previous, ensureProductKeys(imported), report)
+	if len(merged) != 3 {
+		t.Fatalf("после слияния = %+v", merged)
+	}
+	tray, nut, washer := merged[0], merged[1], merged[2]
+	if tray.Name != "Лоток 100х100" || tray.Article != "LP-100" || tray.Category != "Лотки" || tray.VATRate == nil || *tray.VATRate != 20 {
+		t.Errorf("атрибуты лотка потеряны: %+v", tray)
+	}
+	if tray.Price.Kopecks != 47000 || tray.Stock == nil || *tray.Stock != 7 {
+		t.Errorf("цена и остаток лотка не обновлены: %v, %v", tray.Price, tray.Stock)
+	}
+	if nut.Category != "Метизы" || nut.PackSize != 100 || nut.Price.Kopecks != 350 || nut.Stock == nil || *nut.Stock != 3 {
+		t.Errorf("гайка = %+v", nut)
+	}
+	if washer.ExternalID != "p9" || washer.Name != "Шайба М10" || washer.Price.Kopecks != 90 {
+		t.Errorf("новый товар = %+v", washer)
+	}
+}
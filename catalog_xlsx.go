@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/unidoc/unioffice/v2/spreadsheet"
+	"github.com/unidoc/unioffice/v2/spreadsheet/reference"
+)
+
+func xlsxCellText(cell spreadsheet.Cell) string {
+	if cell.IsNumber() {
+		if value, err := cell.GetValueAsNumber(); err == nil {
+			return strconv.FormatFloat(value, 'f', -1, 64)
+		}
+	}
+	if text := cell.GetFormattedValue(); text != "" {
+		return strings.TrimSpace(text)
+	}
+	return strings.TrimSpace(cell.GetString())
+}
+
+func readXLSXSheet(sheet spreadsheet.Sheet, source string) catalogTable {
+	table := catalogTable{
+		source:       source,
+		separator:    "\t",
+		categoryRows: make(map[int]bool),
+	}
+	mergedRows := make(map[uint32]bool)
+	for _, merged := range sheet.MergedCells() {
+		from, to, err := reference.ParseRangeReference(merged.Reference())
+		if err != nil {
+			continue
+		}
+		if from.RowIdx == to.RowIdx && to.ColumnIdx > from.ColumnIdx {
+			mergedRows[from.RowIdx] = true
+		}
+	}
+	maxColumn := sheet.MaxColumnIdx()
+	for _, row := range sheet.Rows() {
+		cells := row.CellsWithEmpty(maxColumn)
+		record := make([]string, len(cells))
+		for i, cell := range cells {
+			record[i] = xlsxCellText(cell)
+		}
+		if mergedRows[row.RowNumber()] && countNonEmptyCells(record) == 1 {
+			table.categoryRows[len(table.records)] = true
+		}
+		table.records = append(table.records, record)
+		table.lines = append(table.lines, int(row.RowNumber()))
+	}
+	return table
+}
+
+func selectXLSXSheet(wb *spreadsheet.Workbook, selector string, columns CatalogColumns) (spreadsheet.Sheet, error) {
+	sheets := wb.Sheets()
+	if len(sheets) == 0 {
+		return spreadsheet.Sheet{}, fmt.Errorf("в книге нет ни одного листа")
+	}
+	if selector != "" {
+		if idx, err := strconv.Atoi(selector); err == nil {
+			if idx < 1 || idx > len(sheets) {
+				return spreadsheet.Sheet{}, fmt.Errorf("лист №%d не найден, в книге %d листов", idx, len(sheets))
+			}
+			return sheets[idx-1], nil
+		}
+		for _, sheet := range sheets {
+			if strings.EqualFold(strings.TrimSpace(sheet.Name()), strings.TrimSpace(selector)) {
+				return sheet, nil
+			}
+		}
+		return spreadsheet.Sheet{}, fmt.Errorf("лист '%s' не найден", selector)
+	}
+	for _, sheet := range sheets {
+		table := readXLSXSheet(sheet, "")
+		if mapping, _ := detectColumns(table.records, columns); mapping.Name >= 0 && mapping.Price >= 0 {
+			return sheet, nil
+		}
+	}
+	return sheets[0], nil
+}
+
+func (a *App) importXLSXCatalog(ctx context.Context, path string, report *ImportReport) ([]Product, error) {
+	wb, err := spreadsheet.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть '%s': %w", path, err)
+	}
+	defer wb.Close()
+
+	cfg := a.config.Catalog
+	sheet, err := selectXLSXSheet(wb, cfg.Sheet, cfg.Columns)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выбора листа в '%s': %w", path, err)
+	}
+	source := fmt.Sprintf("%s [%s]", path, sheet.Name())
+	log.Printf("Импорт '%s': выбран лист '%s'.", path, sheet.Name())
+
+	table := readXLSXSheet(sheet, source)
+	mapping, headerRow := detectColumns(table.records, cfg.Columns)
+	if mapping.Name < 0 || mapping.Price < 0 {
+		if !cfg.LLMFallback {
+			return nil, fmt.Errorf("на листе '%s' не найдены колонки с названием и ценой; задайте catalog.columns в config.json", sheet.Name())
+		}
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: на листе '%s' не найдены колонки с названием и ценой, лист будет разобран через LLM.", sheet.Name())
+		var lines []sourceLine
+		for i, record := range table.records {
+			if countNonEmptyCells(record) == 0 {
+				continue
+			}
+			lines = append(lines, sourceLine{Line: table.lines[i], Text: strings.Join(record, table.separator)})
+		}
+		return a.normalizeCatalogWithLLM(ctx, lines, report)
+	}
+	return a.productsFromTable(ctx, table, mapping, headerRow, report)
+}
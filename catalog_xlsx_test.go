@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/unidoc/unioffice/v2/spreadsheet"
+)
+
+func addXLSXRow(sheet spreadsheet.Sheet, values ...interface{}) {
+	row := sheet.AddRow()
+	for _, value := range values {
+		cell := row.AddCell()
+		switch v := value.(type) {
+		case string:
+			if v != "" {
+				cell.SetString(v)
+			}
+		case float64:
+			cell.SetNumber(v)
+		}
+	}
+}
+
+func testPriceWorkbook() *spreadsheet.Workbook {
+	wb := spreadsheet.New()
+	cover := wb.AddSheet()
+	cover.SetName("Титул")
+	addXLSXRow(cover, "Прайс-лист ООО «Электромонтаж»")
+	addXLSXRow(cover, "Действует с 01.10.2026")
+
+	prices := wb.AddSheet()
+	prices.SetName("Прайс")
+	addXLSXRow(prices, "Наименование", "Артикул", "Ед.", "Цена, руб.")
+	addXLSXRow(prices, "Лотки перфорированные", "", "", "")
+	prices.AddMergedCells("A2", "D2")
+	addXLSXRow(prices, "Лоток перфорированный 100х100", "LP-100", "м", 450.5)
+	addXLSXRow(prices, "Лоток перфорированный 200х100", "LP-200", "м", 612.0)
+	addXLSXRow(prices, "Метизы", "", "", "")
+	prices.AddMergedCells("A5", "D5")
+	addXLSXRow(prices, "Гайка М10", "G-M10", "шт", 3.2)
+
+	archive := wb.AddSheet()
+	archive.SetName("Архив")
+	addXLSXRow(archive, "Наименование", "Цена")
+	addXLSXRow(archive, "Лоток старый", 100.0)
+	return wb
+}
+
+func TestXLSXCellText(t *testing.T) {
+	wb := spreadsheet.New()
+	defer wb.Close()
+	sheet := wb.AddSheet()
+	row := sheet.AddRow()
+	row.AddCell().SetString("  Гайка М10  ")
+	row.AddCell().SetNumber(450.5)
+	row.AddCell().SetNumber(1200)
+	row.AddCell()
+
+	var got []string
+	for _, cell := range sheet.Rows()[0].Cells() {
+		got = append(got, xlsxCellText(cell))
+	}
+	want := []string{"Гайка М10", "450.5", "1200", ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ячейки = %q, ожидалось %q", got, want)
+	}
+}
+
+func TestSelectXLSXSheet(t *testing.T) {
+	wb := testPriceWorkbook()
+	defer wb.Close()
+	tests := []struct {
+		selector string
+		want     string
+		wantErr  bool
+	}{
+		{"", "Прайс", false},
+		{"3", "Архив", false},
+		{" архив ", "Архив", false},
+		{"1", "Титул", false},
+		{"4", "", true},
+		{"0", "", true},
+		{"Склад", "", true},
+	}
+	for _, tt := range tests {
+		sheet, err := selectXLSXSheet(wb, tt.selector, CatalogColumns{})
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("лист %q: ожидалась ошибка, выбран '%s'", tt.selector, sheet.Name())
+			}
+			continue
+		}
+		if err != nil || sheet.Name() != tt.want {
+			t.Errorf("лист %q: выбран '%s' (%v), ожидался '%s'", tt.selector, sheet.Name(), err, tt.want)
+		}
+	}
+
+	empty := spreadsheet.New()
+	defer empty.Close()
+	if _, err := selectXLSXSheet(empty, "", CatalogColumns{}); err == nil {
+		t.Error("книга без листов должна возвращать ошибку")
+	}
+}
+
+func TestReadXLSXSheetMergedCategories(t *testing.T) {
+	wb := testPriceWorkbook()
+	defer wb.Close()
+	sheet, err := selectXLSXSheet(wb, "Прайс", CatalogColumns{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	table := readXLSXSheet(sheet, "price.xlsx [Прайс]")
+	if len(table.records) != 6 || !reflect.DeepEqual(table.lines, []int{1, 2, 3, 4, 5, 6}) {
+		t.Fatalf("строки = %q, номера %v", table.records, table.lines)
+	}
+	if !reflect.DeepEqual(table.categoryRows, map[int]bool{1: true, 4: true}) {
+		t.Errorf("строки-категории = %v", table.categoryRows)
+	}
+	if !reflect.DeepEqual(table.records[2], []string{"Лоток перфорированный 100х100", "LP-100", "м", "450.5"}) {
+		t.Errorf("строка товара = %q", table.records[2])
+	}
+
+	app := newTestApp(nil, &fakeLLMProvider{name: "fake"})
+	app.config.Catalog.Currency = "RUB"
+	mapping, headerRow := detectColumns(table.records, CatalogColumns{})
+	products, err := app.productsFromTable(context.Background(), table, mapping, headerRow, newImportReport(table.source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		name     string
+		category string
+		price    int64
+	}{
+		{"Лоток перфорированный 100х100", "Лотки перфорированные", 45050},
+		{"Лоток перфорированный 200х100", "Лотки перфорированные", 61200},
+		{"Гайка М10", "Метизы", 320},
+	}
+	if len(products) != len(want) {
+		t.Fatalf("товары = %+v", products)
+	}
+	for i, w := range want {
+		if p := products[i]; p.Name != w.name || p.Category != w.category || p.Price.Kopecks != w.price {
+			t.Errorf("товар %d = %q / %q / %d, ожидалось %+v", i, p.Name, p.Category, p.Price.Kopecks, w)
+		}
+	}
+}
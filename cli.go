@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var cliCommands = map[string]func(args []string) int{
+	"generate": runGenerateCommand,
+	"import":   runImportCommand,
+	"search":   runSearchCommand,
+	"batch":    runBatchCommand,
+	"eval":     runEvalCommand,
+}
+
+const cliUsage = `Использование:
+  tkp                                   запуск графического интерфейса
+  tkp generate --query "..." [--out proposal.docx] [--customer ...] [--currency ...] [--override артикул=цена]
+  tkp import [файл каталога]
+  tkp search [--top 10] "запрос"
+  tkp batch [--workers 3] [--out-dir каталог] заявки.xlsx|csv|jsonl
+  tkp eval [--dataset eval.jsonl] [--out результат.json] | tkp eval diff <старый.json> <новый.json>
+
+Справка по флагам команды: tkp <команда> -h`
+
+func runCLI(args []string) (int, bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		fmt.Fprintln(os.Stderr, cliUsage)
+		return 0, true
+	}
+	command, ok := cliCommands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "неизвестная команда '%s'\n\n%s\n", args[0], cliUsage)
+		return 2, true
+	}
+	return command(args[1:]), true
+}
+
+func newCLIApp() *App {
+	app := NewApp()
+	app.loadProductsFromCache()
+	return app
+}
+
+func cliContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt)
+}
+
+type cliReporter struct{}
+
+func (cliReporter) Progress(stage string, percent int, message string, artifact interface{}) {
+	fmt.Fprintf(os.Stderr, "[%3d%%] %s\n", percent, message)
+}
+
+func (cliReporter) Stream(stage string, delta StreamDelta) {}
+
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func parseOverrideFlags(values []string) ([]PriceOverride, error) {
+	var overrides []PriceOverride
+	for _, value := range values {
+		product, amount, ok := strings.Cut(value, "=")
+		product = strings.TrimSpace(product)
+		amount = strings.TrimSpace(amount)
+		if !ok || product == "" || amount == "" {
+			return nil, fmt.Errorf("некорректная ручная цена '%s', ожидается 'артикул=цена' или 'артикул=скидка%%'", value)
+		}
+		number, ok := parsePriceValue(strings.TrimSuffix(amount, "%"))
+		if !ok {
+			return nil, fmt.Errorf("некорректное значение в ручной цене '%s'", value)
+		}
+		override := PriceOverride{Product: product}
+		if strings.HasSuffix(amount, "%") {
+			override.DiscountPercent = &number
+		} else {
+			override.Price = &number
+		}
+		if err := override.validate(); err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, override)
+	}
+	return overrides, nil
+}
+
+func runGenerateCommand(args []string) int {
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	query := flags.String("query", "", "запрос клиента")
+	out := flags.String("out", "", "путь к итоговому DOCX (по умолчанию ТКП_<время>.docx)")
+	customer := flags.String("customer", "", "клиент для персональной скидки")
+	currency := flags.String("currency", "", "валюта предложения (RUB, EUR, USD, CNY)")
+	var overrides stringList
+	flags.Var(&overrides, "override", "ручная цена 'артикул=цена' или 'артикул=скидка%' (можно повторять)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *query == "" {
+		*query = strings.Join(flags.Args(), " ")
+	}
+	if strings.TrimSpace(*query) == "" {
+		fmt.Fprintln(os.Stderr, "Использование: tkp generate --query \"...\" [--out proposal.docx]")
+		return 2
+	}
+	priceOverrides, err := parseOverrideFlags(overrides)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	ctx, cancel := cliContext()
+	defer cancel()
+	app := newCLIApp()
+	options := GenerationOptions{Customer: *customer, Currency: *currency, Overrides: priceOverrides}
+	docx, err := app.generateProposal(withProgressReporter(ctx, cliReporter{}), *query, options)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка генерации: %v\n", err)
+		return 1
+	}
+	path := *out
+	if path == "" {
+		path = fmt.Sprintf("ТКП_%d.docx", time.Now().Unix())
+	}
+	if err := os.WriteFile(path, docx, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "не удалось записать '%s': %v\n", path, err)
+		return 1
+	}
+	fmt.Println(path)
+	return 0
+}
+
+func runImportCommand(args []string) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() > 1 {
+		fmt.Fprintln(os.Stderr, "Использование: tkp import [файл каталога]")
+		return 2
+	}
+
+	ctx, cancel := cliContext()
+	defer cancel()
+	app := newCLIApp()
+	if flags.NArg() == 1 {
+		app.config.Catalog.Source = flags.Arg(0)
+	}
+	app.dataLoadMutex.Lock()
+	diff, err := app.reimportCatalogLocked(ctx)
+	app.dataLoadMutex.Unlock()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка импорта: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Импортировано товаров: %d (добавлено %d, удалено %d, изменена цена %d, без изменений %d)\n",
+		len(app.currentCatalog().products), len(diff.Added), len(diff.Removed), len(diff.Repriced), diff.Unchanged)
+	for _, change := range diff.Repriced {
+		fmt.Printf("  #%d %s: %s -> %s\n", change.ID, change.Name, change.OldPrice, change.NewPrice)
+	}
+	return 0
+}
+
+func runSearchCommand(args []string) int {
+	flags := flag.NewFlagSet("search", flag.ContinueOnError)
+	top := flags.Int("top", 10, "сколько товаров показать")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	query := strings.Join(flags.Args(), " ")
+	if strings.TrimSpace(query) == "" {
+		fmt.Fprintln(os.Stderr, "Использование: tkp search [--top 10] \"запрос\"")
+		return 2
+	}
+
+	ctx, cancel := cliContext()
+	defer cancel()
+	app := newCLIApp()
+	if err := app.ensureDataIsLoaded(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка загрузки каталога: %v\n", err)
+		return 1
+	}
+	products, _ := app.retrieveRelevantProducts(ctx, query, *top)
+	if len(products) == 0 {
+		fmt.Println("Ничего не найдено.")
+		return 0
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tID\tАртикул\tНаименование\tЕд.\tЦена")
+	for i, p := range products {
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\t%s\n", i+1, p.ID, p.Article, p.Name, p.Unit, p.Price)
+	}
+	w.Flush()
+	return 0
+}
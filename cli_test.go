@@ -0,0 +1,69 @@
+package main
+
+import "testing"
+
+func TestRunCLIDispatch(t *testing.T) {
+	tests := []struct {
+		args     []string
+		wantCode int
+		wantCLI  bool
+	}{
+		{nil, 0, false},
+		{[]string{"--help"}, 0, true},
+		{[]string{"-h"}, 0, true},
+		{[]string{"help"}, 0, true},
+		{[]string{"generat"}, 2, true},
+		{[]string{"search"}, 2, true},
+		{[]string{"eval", "diff"}, 2, true},
+	}
+	for _, tt := range tests {
+		code, ok := runCLI(tt.args)
+		if code != tt.wantCode || ok != tt.wantCLI {
+			t.Errorf("runCLI(%q) = %d, %v; ожидалось %d, %v", tt.args, code, ok, tt.wantCode, tt.wantCLI)
+		}
+	}
+}
+
+func TestParseOverrideFlags(t *testing.T) {
+	tests := []struct {
+		value        string
+		wantPrice    float64
+		wantDiscount float64
+		wantErr      bool
+	}{
+		{value: "LP-100=450,50", wantPrice: 450.5},
+		{value: "LP-100=12,5%", wantDiscount: 12.5},
+		{value: "LP-100=0%", wantDiscount: 0},
+		{value: "LP-100=100%", wantErr: true},
+		{value: "LP-100=150%", wantErr: true},
+		{value: "LP-100=-5%", wantErr: true},
+		{value: "LP-100=-10", wantErr: true},
+		{value: "LP-100", wantErr: true},
+		{value: "=10", wantErr: true},
+	}
+	for _, tt := range tests {
+		overrides, err := parseOverrideFlags([]string{tt.value})
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%q: ожидалась ошибка, получено %+v", tt.value, overrides)
+			}
+			continue
+		}
+		if err != nil || len(overrides) != 1 {
+			t.Fatalf("%q: %v", tt.value, err)
+		}
+		override := overrides[0]
+		switch {
+		case override.Price != nil:
+			if *override.Price != tt.wantPrice {
+				t.Errorf("%q: цена %v, ожидалось %v", tt.value, *override.Price, tt.wantPrice)
+			}
+		case override.DiscountPercent != nil:
+			if *override.DiscountPercent != tt.wantDiscount {
+				t.Errorf("%q: скидка %v, ожидалось %v", tt.value, *override.DiscountPercent, tt.wantDiscount)
+			}
+		default:
+			t.Errorf("%q: не задана ни цена, ни скидка", tt.value)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log"
+	"math"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRatesFile       = "cbr_rates.xml"
+	defaultRatesMaxAgeDays = 7
+)
+
+type CurrencyConfig struct {
+	Default    string `json:"default"`
+	RatesFile  string `json:"ratesFile"`
+	MaxAgeDays int    `json:"maxAgeDays"`
+}
+
+type AppliedRate struct {
+	Currency string  `json:"currency"`
+	Nominal  int     `json:"nominal"`
+	Value    float64 `json:"value"`
+	Date     string  `json:"date"`
+}
+
+type cbrValute struct {
+	CharCode string `xml:"CharCode"`
+	Nominal  string `xml:"Nominal"`
+	Value    string `xml:"Value"`
+}
+
+type cbrValCurs struct {
+	XMLName xml.Name    `xml:"ValCurs"`
+	Date    string      `xml:"Date,attr"`
+	Valutes []cbrValute `xml:"Valute"`
+}
+
+type exchangeRate struct {
+	nominal int
+	value   *big.Rat
+}
+
+type exchangeRates struct {
+	date  time.Time
+	rates map[string]exchangeRate
+}
+
+func (c CurrencyConfig) ratesPath() string {
+	if c.RatesFile == "" {
+		return defaultRatesFile
+	}
+	return c.RatesFile
+}
+
+func (c CurrencyConfig) defaultCurrency() string {
+	return normalizeCurrency(c.Default)
+}
+
+func loadExchangeRates(path string) (*exchangeRates, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть файл курсов валют '%s': %w", path, err)
+	}
+	defer file.Close()
+	decoder := xml.NewDecoder(file)
+	decoder.CharsetReader = xmlCharsetReader
+	var curs cbrValCurs
+	if err := decoder.Decode(&curs); err != nil {
+		return nil, fmt.Errorf("ошибка разбора курсов ЦБ '%s': %w", path, err)
+	}
+	date, err := time.Parse("02.01.2006", curs.Date)
+	if err != nil {
+		return nil, fmt.Errorf("некорректная дата курсов ЦБ '%s': %w", curs.Date, err)
+	}
+	rates := &exchangeRates{date: date, rates: make(map[string]exchangeRate)}
+	for _, valute := range curs.Valutes {
+		nominal, err := strconv.Atoi(strings.TrimSpace(valute.Nominal))
+		if err != nil || nominal <= 0 {
+			continue
+		}
+		value, ok := new(big.Rat).SetString(strings.Replace(strings.TrimSpace(valute.Value), ",", ".", 1))
+		if !ok || value.Sign() <= 0 {
+			continue
+		}
+		rates.rates[strings.ToUpper(strings.TrimSpace(valute.CharCode))] = exchangeRate{nominal: nominal, value: value}
+	}
+	return rates, nil
+}
+
+func (r *exchangeRates) rublesPerUnit(currency string) (*big.Rat, error) {
+	if currency == defaultCurrency {
+		return big.NewRat(1, 1), nil
+	}
+	rate, ok := r.rates[currency]
+	if !ok {
+		return nil, fmt.Errorf("в курсах ЦБ на %s нет валюты %s", r.date.Format("02.01.2006"), currency)
+	}
+	return new(big.Rat).Quo(rate.value, big.NewRat(int64(rate.nominal), 1)), nil
+}
+
+func (r *exchangeRates) applied(currency string) AppliedRate {
+	rate := r.rates[currency]
+	value, _ := rate.value.Float64()
+	return AppliedRate{Currency: currency, Nominal: rate.nominal, Value: value, Date: r.date.Format("2006-01-02")}
+}
+
+func (r *exchangeRates) convert(amount Money, target string) (Money, error) {
+	if amount.Currency == target {
+		return amount, nil
+	}
+	from, err := r.rublesPerUnit(amount.Currency)
+	if err != nil {
+		return Money{}, err
+	}
+	to, err := r.rublesPerUnit(target)
+	if err != nil {
+		return Money{}, err
+	}
+	value := new(big.Rat).Mul(big.NewRat(amount.Kopecks, 1), from)
+	value.Quo(value, to)
+	converted, _ := value.Float64()
+	return Money{Kopecks: int64(math.Round(converted)), Currency: target}, nil
+}
+
+type currencyConverter struct {
+	target  string
+	rates   *exchangeRates
+	applied map[string]AppliedRate
+}
+
+func (a *App) newCurrencyConverter(target string) (*currencyConverter, error) {
+	if target == "" {
+		target = a.config.Currency.defaultCurrency()
+	}
+	converter := &currencyConverter{target: normalizeCurrency(target), applied: make(map[string]AppliedRate)}
+	if converter.target == defaultCurrency && !a.catalogHasForeignPrices() {
+		return converter, nil
+	}
+	path := a.config.Currency.ratesPath()
+	rates, err := loadExchangeRates(path)
+	if err != nil {
+		return nil, err
+	}
+	maxAge := a.config.Currency.MaxAgeDays
+	if maxAge <= 0 {
+		maxAge = defaultRatesMaxAgeDays
+	}
+	if age := time.Since(rates.date); age > time.Duration(maxAge)*24*time.Hour {
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: курсы валют в '%s' устарели: дата %s.", path, rates.date.Format("02.01.2006"))
+	}
+	converter.rates = rates
+	return converter, nil
+}
+
+func (a *App) catalogHasForeignPrices() bool {
+	for _, p := range a.currentCatalog().products {
+		if p.Price.Currency != defaultCurrency {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *currencyConverter) convert(amount Money) (Money, error) {
+	if amount.Currency == c.target {
+		return amount, nil
+	}
+	if c.rates == nil {
+		return Money{}, fmt.Errorf("для пересчета %s в %s нужен файл курсов ЦБ", amount.Currency, c.target)
+	}
+	converted, err := c.rates.convert(amount, c.target)
+	if err != nil {
+		return Money{}, err
+	}
+	for _, currency := range []string{amount.Currency, c.target} {
+		if currency != defaultCurrency {
+			c.applied[currency] = c.rates.applied(currency)
+		}
+	}
+	return converted, nil
+}
+
+func (c *currencyConverter) appliedRates() []AppliedRate {
+	var rates []AppliedRate
+	for _, rate := range c.applied {
+		rates = append(rates, rate)
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].Currency < rates[j].Currency })
+	return rates
+}
+
+func (r AppliedRate) String() string {
+	date := r.Date
+	if parsed, err := time.Parse("2006-01-02", r.Date); err == nil {
+		date = parsed.Format("02.01.2006")
+	}
+	value := strings.Replace(strconv.FormatFloat(r.Value, 'f', 4, 64), ".", ",", 1)
+	return fmt.Sprintf("курс ЦБ РФ на %s: %d %s = %s руб.", date, r.Nominal, r.Currency, value)
+}
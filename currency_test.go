@@ -0,0 +1,39 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestExchangeRatesConvert(t *testing.T) {
+	rates := &exchangeRates{rates: map[string]exchangeRate{
+		"USD": {nominal: 1, value: big.NewRat(9025, 100)},
+		"EUR": {nominal: 1, value: big.NewRat(9850, 100)},
+		"CNY": {nominal: 10, value: big.NewRat(12500, 100)},
+	}}
+	tests := []struct {
+		amount  Money
+		target  string
+		want    int64
+		wantErr bool
+	}{
+		{Money{Kopecks: 10000, Currency: "USD"}, "RUB", 902500, false},
+		{Money{Kopecks: 902500, Currency: "RUB"}, "USD", 10000, false},
+		{Money{Kopecks: 10000, Currency: "CNY"}, "RUB", 125000, false},
+		{Money{Kopecks: 10000, Currency: "EUR"}, "USD", 10914, false},
+		{Money{Kopecks: 100, Currency: "RUB"}, "USD", 1, false},
+		{Money{Kopecks: 12345, Currency: "RUB"}, "RUB", 12345, false},
+		{Money{Kopecks: 100, Currency: "GBP"}, "RUB", 0, true},
+		{Money{Kopecks: 100, Currency: "RUB"}, "GBP", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := rates.convert(tt.amount, tt.target)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("convert(%v, %s): ошибка %v", tt.amount, tt.target, err)
+			continue
+		}
+		if !tt.wantErr && (got.Kopecks != tt.want || got.Currency != tt.target) {
+			t.Errorf("convert(%v, %s) = %d %s, ожидалось %d", tt.amount, tt.target, got.Kopecks, got.Currency, tt.want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tcpColumn struct {
+	title string
+	value func(item TCPItem) string
+}
+
+type tcpSummaryRow struct {
+	label string
+	value string
+}
+
+func hasVATItems(items []TCPItem) bool {
+	for _, item := range items {
+		if item.VATRate > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func tcpTableColumns(totals FinalLogResponse) []tcpColumn {
+	items := totals.FoundItems
+	hasVAT := hasVATItems(items)
+	var hasArticle, hasUnit, hasStock, hasRules bool
+	for _, item := range items {
+		hasRules = hasRules || item.Rule != ""
+		hasArticle = hasArticle || item.Article != ""
+		hasUnit = hasUnit || item.Unit != ""
+		hasStock = hasStock || item.Stock != nil
+	}
+	priceTitle, subtotalTitle := "Цена за ед.", "Сумма"
+	if hasVAT && totals.PricesIncludeVAT {
+		priceTitle, subtotalTitle = "Цена за ед. с НДС", "Сумма с НДС"
+	} else if hasVAT {
+		priceTitle, subtotalTitle = "Цена за ед. без НДС", "Сумма без НДС"
+	}
+	var columns []tcpColumn
+	if hasArticle {
+		columns = append(columns, tcpColumn{"Артикул", func(item TCPItem) string { return item.Article }})
+	}
+	columns = append(columns, tcpColumn{"Наименование", func(item TCPItem) string { return item.Name }})
+	if hasUnit {
+		columns = append(columns, tcpColumn{"Ед. изм.", func(item TCPItem) string { return item.Unit }})
+	}
+	columns = append(columns, tcpColumn{"Кол-во", func(item TCPItem) string { return strconv.Itoa(item.Quantity) }})
+	if hasRules {
+		columns = append(columns, tcpColumn{"Цена по прайсу", func(item TCPItem) string { return item.BasePrice.String() }})
+	}
+	columns = append(columns, tcpColumn{priceTitle, func(item TCPItem) string { return item.Price.String() }})
+	if hasRules {
+		columns = append(columns, tcpColumn{"Условие цены", func(item TCPItem) string { return item.Rule }})
+	}
+	if hasStock {
+		columns = append(columns, tcpColumn{"Наличие", stockLabel})
+	}
+	columns = append(columns, tcpColumn{subtotalTitle, func(item TCPItem) string { return item.Subtotal.String() }})
+	if !hasVAT {
+		return columns
+	}
+	columns = append(columns,
+		tcpColumn{"Ставка НДС", func(item TCPItem) string { return formatVATRate(item.VATRate) }},
+		tcpColumn{"Сумма НДС", func(item TCPItem) string { return item.VATAmount.String() }},
+	)
+	if !totals.PricesIncludeVAT {
+		columns = append(columns, tcpColumn{"Всего с НДС", func(item TCPItem) string { return item.Total.String() }})
+	}
+	return columns
+}
+
+func tcpSummaryRows(totals FinalLogResponse) []tcpSummaryRow {
+	var rows []tcpSummaryRow
+	switch {
+	case !hasVATItems(totals.FoundItems):
+		rows = []tcpSummaryRow{
+			{"Итого:", totals.TotalCost.String()},
+			{"НДС:", "не облагается"},
+		}
+	case totals.PricesIncludeVAT:
+		rows = []tcpSummaryRow{
+			{"Итого:", totals.TotalCost.String()},
+			{"В том числе НДС:", totals.TotalVAT.String()},
+		}
+	default:
+		rows = []tcpSummaryRow{
+			{"Итого без НДС:", totals.TotalWithoutVAT.String()},
+			{"НДС:", totals.TotalVAT.String()},
+			{"Всего с НДС:", totals.TotalCost.String()},
+		}
+	}
+	for _, rate := range totals.ExchangeRates {
+		rows = append(rows, tcpSummaryRow{"Пересчет по курсу:", rate.String()})
+	}
+	return rows
+}
+
+func exchangeRatesText(rates []AppliedRate) string {
+	parts := make([]string, len(rates))
+	for i, rate := range rates {
+		parts[i] = rate.String()
+	}
+	return strings.Join(parts, "; ")
+}
+
+func formatVATRate(rate int) string {
+	if rate == 0 {
+		return "без НДС"
+	}
+	return fmt.Sprintf("%d%%", rate)
+}
+
+func stockLabel(item TCPItem) string {
+	switch {
+	case item.Stock == nil:
+		return ""
+	case *item.Stock >= float64(item.Quantity):
+		return "в наличии"
+	case *item.Stock > 0:
+		return fmt.Sprintf("в наличии %s %s", formatQuantity(*item.Stock), item.Unit)
+	default:
+		return "под заказ"
+	}
+}
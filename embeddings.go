@@ -0,0 +1,394 @@
+package main
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultEmbeddingsFile     = "embeddings.json"
+	defaultEmbeddingsModel    = "nomic-embed-text"
+	defaultEmbeddingsWeight   = 0.4
+	defaultEmbeddingsWorkers  = 4
+	defaultEmbeddingsTimeout  = 30 * time.Second
+	defaultEmbeddingsMinScore = 0.3
+)
+
+type EmbeddingsConfig struct {
+	Enabled        bool    `json:"enabled"`
+	BaseURL        string  `json:"baseURL"`
+	Model          string  `json:"model"`
+	IndexFile      string  `json:"indexFile"`
+	Weight         float64 `json:"weight"`
+	MinSimilarity  float64 `json:"minSimilarity"`
+	Workers        int     `json:"workers"`
+	TimeoutSeconds int     `json:"timeoutSeconds"`
+}
+
+type OllamaEmbeddingRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+}
+
+type OllamaEmbeddingResponse struct {
+	Embedding []float32 `json:"embedding"`
+	Error     string    `json:"error,omitempty"`
+}
+
+type embeddingEntry struct {
+	TextHash string    `json:"textHash"`
+	Vector   []float32 `json:"vector"`
+}
+
+type embeddingIndexFile struct {
+	Model       string                    `json:"model"`
+	CatalogHash string                    `json:"catalogHash"`
+	Vectors     map[string]embeddingEntry `json:"vectors"`
+}
+
+type vectorIndex struct {
+	keys    []string
+	vectors [][]float32
+}
+
+func (c EmbeddingsConfig) baseURL(ollama OllamaConfig) string {
+	if c.BaseURL != "" {
+		return c.BaseURL
+	}
+	return ollama.BaseURL
+}
+
+func (c EmbeddingsConfig) model() string {
+	if c.Model == "" {
+		return defaultEmbeddingsModel
+	}
+	return c.Model
+}
+
+func (c EmbeddingsConfig) indexPath() string {
+	if c.IndexFile == "" {
+		return defaultEmbeddingsFile
+	}
+	return c.IndexFile
+}
+
+func (c EmbeddingsConfig) weight() float64 {
+	if c.Weight <= 0 || c.Weight > 1 {
+		return defaultEmbeddingsWeight
+	}
+	return c.Weight
+}
+
+func (c EmbeddingsConfig) minSimilarity() float64 {
+	if c.MinSimilarity <= 0 {
+		return defaultEmbeddingsMinScore
+	}
+	return c.MinSimilarity
+}
+
+func (c EmbeddingsConfig) timeout() time.Duration {
+	if c.TimeoutSeconds <= 0 {
+		return defaultEmbeddingsTimeout
+	}
+	return time.Duration(c.TimeoutSeconds) * time.Second
+}
+
+func catalogHash(data []byte) string {
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func embeddingText(p Product) string {
+	parts := []string{p.Name}
+	if p.Category != "" {
+		parts = append(parts, "Категория: "+p.Category)
+	}
+	if p.Article != "" {
+		parts = append(parts, "Артикул: "+p.Article)
+	}
+	return strings.Join(parts, ". ")
+}
+
+func normalizeVector(vector []float32) []float32 {
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		return vector
+	}
+	norm = math.Sqrt(norm)
+	normalized := make([]float32, len(vector))
+	for i, v := range vector {
+		normalized[i] = float32(float64(v) / norm)
+	}
+	return normalized
+}
+
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot
+}
+
+func (a *App) embedText(ctx context.Context, text string) ([]float32, error) {
+	cfg := a.config.Embeddings
+	ctx, cancel := context.WithTimeout(ctx, cfg.timeout())
+	defer cancel()
+	resp, err := a.httpClient.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/json").
+		SetBody(OllamaEmbeddingRequest{Model: cfg.model(), Prompt: text}).
+		Post(cfg.baseURL(a.config.Ollama) + "/api/embeddings")
+	if err != nil {
+		return nil, fmt.Errorf("сетевая ошибка при запросе эмбеддинга Ollama: %w", err)
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("ошибка от API эмбеддингов Ollama: %s - %s", resp.Status(), resp.String())
+	}
+	var embedding OllamaEmbeddingResponse
+	if err := json.Unmarshal(resp.Body(), &embedding); err != nil {
+		return nil, fmt.Errorf("ошибка парсинга ответа эмбеддингов Ollama: %w", err)
+	}
+	if embedding.Error != "" {
+		return nil, fmt.Errorf("Ollama вернула ошибку эмбеддинга: %s", embedding.Error)
+	}
+	if len(embedding.Embedding) == 0 {
+		return nil, fmt.Errorf("Ollama вернула пустой эмбеддинг")
+	}
+	return normalizeVector(embedding.Embedding), nil
+}
+
+func loadEmbeddingIndexFile(path string) (*embeddingIndexFile, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &embeddingIndexFile{Vectors: make(map[string]embeddingEntry)}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать '%s': %w", path, err)
+	}
+	var file embeddingIndexFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: индекс эмбеддингов '%s' поврежден и будет пересчитан: %v", path, err)
+		return &embeddingIndexFile{Vectors: make(map[string]embeddingEntry)}, nil
+	}
+	if file.Vectors == nil {
+		file.Vectors = make(map[string]embeddingEntry)
+	}
+	return &file, nil
+}
+
+func (f *embeddingIndexFile) save(path string) error {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("не удалось сформировать JSON индекса эмбеддингов: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("не удалось записать '%s': %w", path, err)
+	}
+	return nil
+}
+
+func (a *App) refreshEmbeddingsAsync(products []Product, hash string) {
+	if !a.config.Embeddings.Enabled {
+		return
+	}
+	ctx, cancel := context.WithCancel(a.baseContext())
+	a.vectorsMutex.Lock()
+	if a.vectorsCancel != nil {
+		a.vectorsCancel()
+	}
+	a.vectorsCancel = cancel
+	a.vectorsMutex.Unlock()
+	go func() {
+		defer cancel()
+		a.vectorsBuild.Lock()
+		defer a.vectorsBuild.Unlock()
+		err := a.refreshEmbeddings(ctx, products, hash)
+		switch {
+		case errors.Is(err, context.Canceled):
+			log.Println("Построение семантического индекса прервано: каталог обновился или приложение закрывается.")
+		case err != nil:
+			log.Printf("ПРЕДУПРЕЖДЕНИЕ: семантический индекс не построен, используется только поиск по ключевым словам: %v", err)
+		}
+	}()
+}
+
+func (a *App) refreshEmbeddings(ctx context.Context, products []Product, hash string) error {
+	cfg := a.config.Embeddings
+	path := cfg.indexPath()
+	file, err := loadEmbeddingIndexFile(path)
+	if err != nil {
+		return err
+	}
+	if file.Model != cfg.model() {
+		file = &embeddingIndexFile{Model: cfg.model(), Vectors: make(map[string]embeddingEntry)}
+	}
+
+	texts := make(map[string]string, len(products))
+	var pending []Product
+	for _, p := range products {
+		text := embeddingText(p)
+		texts[p.Key] = catalogHash([]byte(text))
+		if entry, ok := file.Vectors[p.Key]; !ok || entry.TextHash != texts[p.Key] {
+			pending = append(pending, p)
+		}
+	}
+	if file.CatalogHash != hash || len(pending) > 0 {
+		log.Printf("Индекс эмбеддингов устарел: нужно пересчитать %d из %d товаров (модель %s).", len(pending), len(products), cfg.model())
+	}
+
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = defaultEmbeddingsWorkers
+	}
+	var (
+		mutex    sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	jobs := make(chan Product)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range jobs {
+				vector, err := a.embedText(ctx, embeddingText(p))
+				mutex.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+					}
+				} else {
+					file.Vectors[p.Key] = embeddingEntry{TextHash: texts[p.Key], Vector: vector}
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	for _, p := range pending {
+		mutex.Lock()
+		failed := firstErr != nil
+		mutex.Unlock()
+		if failed || ctx.Err() != nil {
+			break
+		}
+		jobs <- p
+	}
+	close(jobs)
+	wg.Wait()
+
+	for key := range file.Vectors {
+		if _, ok := texts[key]; !ok {
+			delete(file.Vectors, key)
+		}
+	}
+	if firstErr == nil && ctx.Err() == nil {
+		file.CatalogHash = hash
+	}
+	if len(pending) > 0 {
+		if err := file.save(path); err != nil {
+			log.Printf("ПРЕДУПРЕЖДЕНИЕ: %v", err)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	index := &vectorIndex{}
+	for _, p := range products {
+		if entry, ok := file.Vectors[p.Key]; ok {
+			index.keys = append(index.keys, p.Key)
+			index.vectors = append(index.vectors, entry.Vector)
+		}
+	}
+	a.vectorsMutex.Lock()
+	a.vectors = index
+	a.vectorsMutex.Unlock()
+	log.Printf("Семантический индекс готов: %d из %d товаров.", len(index.keys), len(products))
+	return firstErr
+}
+
+func (a *App) semanticSearch(ctx context.Context, query string, topK int) []scoredProduct {
+	a.vectorsMutex.RLock()
+	index := a.vectors
+	a.vectorsMutex.RUnlock()
+	if index == nil || len(index.keys) == 0 {
+		return nil
+	}
+	queryVector, err := a.embedText(ctx, query)
+	if err != nil {
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: не удалось получить эмбеддинг запроса: %v", err)
+		return nil
+	}
+	catalog := a.currentCatalog()
+	minScore := a.config.Embeddings.minSimilarity()
+	var results []scoredProduct
+	for i, vector := range index.vectors {
+		product, ok := catalog.productByKey(index.keys[i])
+		if !ok {
+			continue
+		}
+		if score := cosineSimilarity(queryVector, vector); score >= minScore {
+			results = append(results, scoredProduct{Product: product, Score: score})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > topK {
+		results = results[:topK]
+	}
+	return results
+}
+
+func hybridRank(keyword, semantic []scoredProduct, weight float64, topK int) []scoredProduct {
+	maxKeyword := 0.0
+	for _, r := range keyword {
+		maxKeyword = math.Max(maxKeyword, r.Score)
+	}
+	combined := make(map[int]*scoredProduct)
+	for _, r := range keyword {
+		score := 0.0
+		if maxKeyword > 0 {
+			score = (1 - weight) * r.Score / maxKeyword
+		}
+		combined[r.Product.ID] = &scoredProduct{Product: r.Product, Score: score}
+	}
+	for _, r := range semantic {
+		if existing, ok := combined[r.Product.ID]; ok {
+			existing.Score += weight * r.Score
+			continue
+		}
+		combined[r.Product.ID] = &scoredProduct{Product: r.Product, Score: weight * r.Score}
+	}
+	results := make([]scoredProduct, 0, len(combined))
+	for _, r := range combined {
+		results = append(results, *r)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Product.ID < results[j].Product.ID
+	})
+	if len(results) > topK {
+		results = results[:topK]
+	}
+	return results
+}
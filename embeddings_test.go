@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+)
+
+func TestSemanticSearchResolvesCurrentCatalog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"embedding": [1, 0]}`))
+	}))
+	defer server.Close()
+
+	products := ensureProductKeys(testCatalog())
+	app := newTestApp(products, &fakeLLMProvider{name: "fake"})
+	app.httpClient = resty.New()
+	app.config.Embeddings.BaseURL = server.URL
+	app.vectors = &vectorIndex{
+		keys:    []string{products[0].Key, products[1].Key, products[2].Key},
+		vectors: [][]float32{{1, 0}, {0.8, 0.6}, {0.6, 0.8}},
+	}
+
+	repriced := []Product{products[0], products[1]}
+	repriced[0].Price = Money{Kopecks: 50000, Currency: "RUB"}
+	repriced[1].Name = "Гайка М10 оцинкованная"
+	app.setCatalog(repriced)
+
+	results := app.semanticSearch(context.Background(), "лоток", 5)
+	if len(results) != 2 {
+		t.Fatalf("удаленный товар не должен попадать в выдачу: %+v", results)
+	}
+	if results[0].Product.ID != 1 || results[0].Product.Price.Kopecks != 50000 {
+		t.Errorf("первым должен быть лоток с новой ценой: %+v", results[0].Product)
+	}
+	if results[1].Product.ID != 2 || results[1].Product.Name != "Гайка М10 оцинкованная" {
+		t.Errorf("вторым должен быть переименованный товар: %+v", results[1].Product)
+	}
+}
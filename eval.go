@@ -0,0 +1,478 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+type EvalExpectedItem struct {
+	ID       int `json:"id"`
+	Quantity int `json:"quantity"`
+}
+
+type EvalCase struct {
+	Name     string             `json:"name"`
+	Query    string             `json:"query"`
+	Customer string             `json:"customer,omitempty"`
+	Expected []EvalExpectedItem `json:"expected"`
+}
+
+type EvalCaseResult struct {
+	Name             string             `json:"name"`
+	Query            string             `json:"query"`
+	Retrieved        []int              `json:"retrieved"`
+	RecallAtK        map[int]float64    `json:"recallAtK"`
+	Found            []EvalExpectedItem `json:"found,omitempty"`
+	Matched          int                `json:"matched"`
+	Precision        float64            `json:"precision"`
+	Recall           float64            `json:"recall"`
+	QuantityAccuracy float64            `json:"quantityAccuracy"`
+	ExpectedCost     Money              `json:"expectedCost"`
+	ActualCost       Money              `json:"actualCost"`
+	CostError        float64            `json:"costError"`
+	DurationMs       int64              `json:"durationMs"`
+	Error            string             `json:"error,omitempty"`
+}
+
+type EvalSummary struct {
+	Cases            int             `json:"cases"`
+	Failed           int             `json:"failed"`
+	RecallAtK        map[int]float64 `json:"recallAtK"`
+	Precision        float64         `json:"precision"`
+	Recall           float64         `json:"recall"`
+	QuantityAccuracy float64         `json:"quantityAccuracy"`
+	CostError        float64         `json:"costError"`
+}
+
+type EvalRun struct {
+	Dataset       string           `json:"dataset"`
+	Provider      string           `json:"provider"`
+	StartedAt     time.Time        `json:"startedAt"`
+	RetrievalOnly bool             `json:"retrievalOnly"`
+	K             []int            `json:"k"`
+	Summary       EvalSummary      `json:"summary"`
+	Cases         []EvalCaseResult `json:"cases"`
+}
+
+func loadEvalDataset(path string) ([]EvalCase, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть набор данных '%s': %w", path, err)
+	}
+	defer file.Close()
+	var cases []EvalCase
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var c EvalCase
+		if err := json.Unmarshal([]byte(text), &c); err != nil {
+			return nil, fmt.Errorf("'%s', строка %d: %w", path, line, err)
+		}
+		if strings.TrimSpace(c.Query) == "" {
+			return nil, fmt.Errorf("'%s', строка %d: пустой запрос", path, line)
+		}
+		if c.Name == "" {
+			c.Name = fmt.Sprintf("case-%d", len(cases)+1)
+		}
+		cases = append(cases, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения '%s': %w", path, err)
+	}
+	if len(cases) == 0 {
+		return nil, fmt.Errorf("в '%s' нет ни одного примера", path)
+	}
+	return cases, nil
+}
+
+func parseEvalK(value string) ([]int, error) {
+	var ks []int
+	for _, part := range strings.Split(value, ",") {
+		k, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || k <= 0 {
+			return nil, fmt.Errorf("некорректное значение k: '%s'", part)
+		}
+		ks = append(ks, k)
+	}
+	sort.Ints(ks)
+	return ks, nil
+}
+
+func recallAt(expected []EvalExpectedItem, retrieved []int, k int) float64 {
+	if len(expected) == 0 {
+		return 1
+	}
+	if k > len(retrieved) {
+		k = len(retrieved)
+	}
+	top := make(map[int]bool, k)
+	for _, id := range retrieved[:k] {
+		top[id] = true
+	}
+	hits := 0
+	for _, item := range expected {
+		if top[item.ID] {
+			hits++
+		}
+	}
+	return float64(hits) / float64(len(expected))
+}
+
+func (a *App) expectedCost(c EvalCase, converter *currencyConverter) (Money, error) {
+	total := Money{Currency: converter.target}
+	catalog := a.currentCatalog()
+	for _, item := range c.Expected {
+		product, ok := catalog.productMap[item.ID]
+		if !ok {
+			continue
+		}
+		pricing := a.config.Pricing.priceFor(product, item.Quantity, GenerationOptions{Customer: c.Customer})
+		price, err := converter.convert(pricing.price)
+		if err != nil {
+			return Money{}, err
+		}
+		total = total.Add(price.Mul(item.Quantity))
+	}
+	return total, nil
+}
+
+func (a *App) evaluateCase(ctx context.Context, c EvalCase, ks []int, retrievalOnly bool, converter *currencyConverter) (result EvalCaseResult) {
+	started := time.Now()
+	result = EvalCaseResult{Name: c.Name, Query: c.Query, RecallAtK: make(map[int]float64)}
+	defer func() { result.DurationMs = time.Since(started).Milliseconds() }()
+
+	catalog := a.currentCatalog()
+	for _, item := range c.Expected {
+		if _, ok := catalog.productMap[item.ID]; !ok {
+			log.Printf("ПРЕДУПРЕЖДЕНИЕ: пример '%s' ссылается на отсутствующий в каталоге ID %d.", c.Name, item.ID)
+		}
+	}
+	relevantProducts, _ := a.retrieveRelevantProducts(ctx, c.Query, retrievalTopK)
+	for _, p := range relevantProducts {
+		result.Retrieved = append(result.Retrieved, p.ID)
+	}
+	for _, k := range ks {
+		result.RecallAtK[k] = recallAt(c.Expected, result.Retrieved, k)
+	}
+	if retrievalOnly {
+		return result
+	}
+
+	expectedCost, err := a.expectedCost(c, converter)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.ExpectedCost = expectedCost
+	built, err := a.assembleProposal(ctx, c.Query, relevantProducts, converter, GenerationOptions{Customer: c.Customer})
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	expected := make(map[int]int, len(c.Expected))
+	for _, item := range c.Expected {
+		expected[item.ID] = item.Quantity
+	}
+	actualCost := Money{Currency: converter.target}
+	correctQuantity := 0
+	for _, item := range built.totals.FoundItems {
+		result.Found = append(result.Found, EvalExpectedItem{ID: item.ProductID, Quantity: item.Quantity})
+		actualCost = actualCost.Add(item.Subtotal)
+		quantity, ok := expected[item.ProductID]
+		if !ok {
+			continue
+		}
+		result.Matched++
+		if quantity == item.Quantity {
+			correctQuantity++
+		}
+	}
+	result.ActualCost = actualCost
+	if len(result.Found) > 0 {
+		result.Precision = float64(result.Matched) / float64(len(result.Found))
+	}
+	if len(c.Expected) > 0 {
+		result.Recall = float64(result.Matched) / float64(len(c.Expected))
+	}
+	if result.Matched > 0 {
+		result.QuantityAccuracy = float64(correctQuantity) / float64(result.Matched)
+	}
+	if !expectedCost.IsZero() {
+		result.CostError = math.Abs(float64(actualCost.Kopecks-expectedCost.Kopecks)) / float64(expectedCost.Kopecks)
+	}
+	return result
+}
+
+func summarizeEval(results []EvalCaseResult, ks []int, retrievalOnly bool) EvalSummary {
+	summary := EvalSummary{Cases: len(results), RecallAtK: make(map[int]float64)}
+	pipelineCases, quantityCases := 0, 0
+	for _, r := range results {
+		for _, k := range ks {
+			summary.RecallAtK[k] += r.RecallAtK[k]
+		}
+		if r.Error != "" {
+			summary.Failed++
+			continue
+		}
+		if retrievalOnly {
+			continue
+		}
+		pipelineCases++
+		summary.Precision += r.Precision
+		summary.Recall += r.Recall
+		summary.CostError += r.CostError
+		if r.Matched > 0 {
+			quantityCases++
+			summary.QuantityAccuracy += r.QuantityAccuracy
+		}
+	}
+	if len(results) > 0 {
+		for _, k := range ks {
+			summary.RecallAtK[k] /= float64(len(results))
+		}
+	}
+	if pipelineCases > 0 {
+		summary.Precision /= float64(pipelineCases)
+		summary.Recall /= float64(pipelineCases)
+		summary.CostError /= float64(pipelineCases)
+	}
+	if quantityCases > 0 {
+		summary.QuantityAccuracy /= float64(quantityCases)
+	}
+	return summary
+}
+
+func (a *App) runEvaluation(ctx context.Context, dataset string, ks []int, retrievalOnly bool) (EvalRun, error) {
+	cases, err := loadEvalDataset(dataset)
+	if err != nil {
+		return EvalRun{}, err
+	}
+	if err := a.ensureDataIsLoaded(ctx); err != nil {
+		return EvalRun{}, err
+	}
+	converter, err := a.newCurrencyConverter("")
+	if err != nil {
+		return EvalRun{}, err
+	}
+	run := EvalRun{
+		Dataset:       dataset,
+		Provider:      a.llm.Name(),
+		StartedAt:     time.Now(),
+		RetrievalOnly: retrievalOnly,
+		K:             ks,
+	}
+	for i, c := range cases {
+		log.Printf("Оценка %d/%d: %s", i+1, len(cases), c.Name)
+		result := a.evaluateCase(ctx, c, ks, retrievalOnly, converter)
+		if result.Error != "" {
+			log.Printf("ПРЕДУПРЕЖДЕНИЕ: пример '%s' завершился с ошибкой: %s", c.Name, result.Error)
+		}
+		run.Cases = append(run.Cases, result)
+		if err := ctx.Err(); err != nil {
+			return EvalRun{}, err
+		}
+	}
+	run.Summary = summarizeEval(run.Cases, ks, retrievalOnly)
+	return run, nil
+}
+
+func loadEvalRun(path string) (EvalRun, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return EvalRun{}, fmt.Errorf("не удалось прочитать результаты '%s': %w", path, err)
+	}
+	var run EvalRun
+	if err := json.Unmarshal(data, &run); err != nil {
+		return EvalRun{}, fmt.Errorf("файл результатов '%s' поврежден: %w", path, err)
+	}
+	return run, nil
+}
+
+func (run EvalRun) save(path string) error {
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return fmt.Errorf("не удалось сформировать JSON результатов оценки: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("не удалось записать '%s': %w", path, err)
+	}
+	return nil
+}
+
+func formatRatio(value float64) string {
+	return strconv.FormatFloat(value*100, 'f', 1, 64) + "%"
+}
+
+func formatDelta(before, after float64) string {
+	delta := (after - before) * 100
+	if math.Abs(delta) < 0.05 {
+		return "="
+	}
+	return fmt.Sprintf("%+.1f п.п.", delta)
+}
+
+func (run EvalRun) printReport() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := []string{"Пример"}
+	for _, k := range run.K {
+		header = append(header, fmt.Sprintf("R@%d", k))
+	}
+	if !run.RetrievalOnly {
+		header = append(header, "Точность", "Полнота", "Кол-во", "Ошибка суммы")
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	row := func(name string, recallAtK map[int]float64, precision, recall, quantity, cost float64, failure string) {
+		cells := []string{name}
+		for _, k := range run.K {
+			cells = append(cells, formatRatio(recallAtK[k]))
+		}
+		if !run.RetrievalOnly {
+			if failure != "" {
+				cells = append(cells, "ошибка: "+failure)
+			} else {
+				cells = append(cells, formatRatio(precision), formatRatio(recall), formatRatio(quantity), formatRatio(cost))
+			}
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	for _, r := range run.Cases {
+		row(r.Name, r.RecallAtK, r.Precision, r.Recall, r.QuantityAccuracy, r.CostError, r.Error)
+	}
+	s := run.Summary
+	row("ИТОГО", s.RecallAtK, s.Precision, s.Recall, s.QuantityAccuracy, s.CostError, "")
+	w.Flush()
+	fmt.Printf("Примеров: %d, с ошибкой: %d, провайдер: %s\n", s.Cases, s.Failed, run.Provider)
+}
+
+func printEvalDiff(baseline, candidate EvalRun) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Метрика\tБыло\tСтало\tИзменение")
+	metric := func(name string, oldValue, newValue float64) {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, formatRatio(oldValue), formatRatio(newValue), formatDelta(oldValue, newValue))
+	}
+	for _, k := range candidate.K {
+		if _, ok := baseline.Summary.RecallAtK[k]; ok {
+			metric(fmt.Sprintf("R@%d", k), baseline.Summary.RecallAtK[k], candidate.Summary.RecallAtK[k])
+		}
+	}
+	if !baseline.RetrievalOnly && !candidate.RetrievalOnly {
+		metric("Точность", baseline.Summary.Precision, candidate.Summary.Precision)
+		metric("Полнота", baseline.Summary.Recall, candidate.Summary.Recall)
+		metric("Кол-во", baseline.Summary.QuantityAccuracy, candidate.Summary.QuantityAccuracy)
+		metric("Ошибка суммы", baseline.Summary.CostError, candidate.Summary.CostError)
+	}
+	w.Flush()
+
+	previous := make(map[string]EvalCaseResult, len(baseline.Cases))
+	for _, r := range baseline.Cases {
+		previous[r.Name] = r
+	}
+	recallHeader := "R@K"
+	k := 0
+	if ks := candidate.K; len(ks) > 0 {
+		k = ks[len(ks)-1]
+		recallHeader = fmt.Sprintf("R@%d", k)
+	}
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Изменившиеся примеры\t%s\tТочность\tКол-во\tОшибка суммы\n", recallHeader)
+	changed := 0
+	for _, r := range candidate.Cases {
+		p, ok := previous[r.Name]
+		if !ok {
+			fmt.Fprintf(w, "%s\tновый пример\t\t\t\n", r.Name)
+			changed++
+			continue
+		}
+		if p.RecallAtK[k] == r.RecallAtK[k] && p.Precision == r.Precision && p.QuantityAccuracy == r.QuantityAccuracy && p.CostError == r.CostError && p.Error == r.Error {
+			continue
+		}
+		changed++
+		if r.Error != "" {
+			fmt.Fprintf(w, "%s\tошибка: %s\t\t\t\n", r.Name, r.Error)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Name,
+			formatDelta(p.RecallAtK[k], r.RecallAtK[k]),
+			formatDelta(p.Precision, r.Precision),
+			formatDelta(p.QuantityAccuracy, r.QuantityAccuracy),
+			formatDelta(p.CostError, r.CostError))
+	}
+	w.Flush()
+	if changed == 0 {
+		fmt.Println("Результаты по примерам не изменились.")
+	}
+}
+
+func runEvalCommand(args []string) int {
+	if len(args) > 0 && args[0] == "diff" {
+		if len(args) != 3 {
+			fmt.Fprintln(os.Stderr, "Использование: tkp eval diff <старый.json> <новый.json>")
+			return 2
+		}
+		baseline, err := loadEvalRun(args[1])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		candidate, err := loadEvalRun(args[2])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		printEvalDiff(baseline, candidate)
+		return 0
+	}
+
+	flags := flag.NewFlagSet("eval", flag.ContinueOnError)
+	dataset := flags.String("dataset", "eval.jsonl", "размеченный набор запросов в формате JSONL")
+	out := flags.String("out", "", "файл для сохранения результатов (по умолчанию eval_<время>.json)")
+	kValue := flags.String("k", "5,10,20,50", "значения k для recall@k через запятую")
+	retrievalOnly := flags.Bool("retrieval-only", false, "оценивать только поиск, без вызова LLM для комплектации")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	ks, err := parseEvalK(*kValue)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	ctx, cancel := cliContext()
+	defer cancel()
+	app := newCLIApp()
+	run, err := app.runEvaluation(ctx, *dataset, ks, *retrievalOnly)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка оценки: %v\n", err)
+		return 1
+	}
+	path := *out
+	if path == "" {
+		path = fmt.Sprintf("eval_%d.json", run.StartedAt.Unix())
+	}
+	if err := run.save(path); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	run.printReport()
+	fmt.Printf("Результаты сохранены в '%s'.\n", path)
+	return 0
+}
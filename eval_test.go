@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"math"
+	"os"
+	"testing"
+)
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestRecallAt(t *testing.T) {
+	expected := []EvalExpectedItem{{ID: 1, Quantity: 12}, {ID: 3, Quantity: 5}}
+	tests := []struct {
+		name      string
+		expected  []EvalExpectedItem
+		retrieved []int
+		k         int
+		want      float64
+	}{
+		{"оба в топе", expected, []int{3, 1, 2}, 2, 1},
+		{"один за границей k", expected, []int{1, 2, 3}, 2, 0.5},
+		{"k больше выдачи", expected, []int{3}, 10, 0.5},
+		{"пустая выдача", expected, nil, 5, 0},
+		{"k = 0", expected, []int{1, 3}, 0, 0},
+		{"нет ожидаемых", nil, []int{1}, 1, 1},
+	}
+	for _, tt := range tests {
+		if got := recallAt(tt.expected, tt.retrieved, tt.k); !approxEqual(got, tt.want) {
+			t.Errorf("%s: recall@%d = %v, ожидалось %v", tt.name, tt.k, got, tt.want)
+		}
+	}
+}
+
+func TestSummarizeEval(t *testing.T) {
+	results := []EvalCaseResult{
+		{Name: "1", RecallAtK: map[int]float64{1: 1, 5: 1}, Matched: 1, Precision: 1, Recall: 0.5, QuantityAccuracy: 1, CostError: 0.2},
+		{Name: "2", RecallAtK: map[int]float64{1: 0, 5: 0.5}, Matched: 2, Precision: 0.5, Recall: 1, QuantityAccuracy: 0.5, CostError: 0.1},
+		{Name: "3", RecallAtK: map[int]float64{1: 0, 5: 0.5}, Error: "таймаут"},
+		{Name: "4", RecallAtK: map[int]float64{1: 1, 5: 1}, CostError: 1},
+	}
+
+	summary := summarizeEval(results, []int{1, 5}, false)
+	if summary.Cases != 4 || summary.Failed != 1 {
+		t.Errorf("примеров %d, с ошибкой %d", summary.Cases, summary.Failed)
+	}
+	checks := []struct {
+		name      string
+		got, want float64
+	}{
+		{"R@1", summary.RecallAtK[1], 0.5},
+		{"R@5", summary.RecallAtK[5], 0.75},
+		{"точность", summary.Precision, 0.5},
+		{"полнота", summary.Recall, 0.5},
+		{"ошибка суммы", summary.CostError, 1.3 / 3},
+		{"точность количеств", summary.QuantityAccuracy, 0.75},
+	}
+	for _, c := range checks {
+		if !approxEqual(c.got, c.want) {
+			t.Errorf("%s = %v, ожидалось %v", c.name, c.got, c.want)
+		}
+	}
+
+	retrieval := summarizeEval(results, []int{1, 5}, true)
+	if !approxEqual(retrieval.RecallAtK[5], 0.75) || retrieval.Precision != 0 || retrieval.CostError != 0 || retrieval.Failed != 1 {
+		t.Errorf("только поиск: %+v", retrieval)
+	}
+
+	if empty := summarizeEval(nil, []int{1}, false); empty.Cases != 0 || empty.RecallAtK[1] != 0 || empty.Precision != 0 {
+		t.Errorf("пустой прогон: %+v", empty)
+	}
+}
+
+func TestEvaluateCase(t *testing.T) {
+	llm := &fakeLLMProvider{name: "fake", replies: []string{
+		`{"keywords": ["лоток", "гайка"]}`,
+		"- Лоток перфорированный 100х100, 12\n- Гайка М10, 100",
+		`{"found_items": [{"id": 1, "quantity": 12}, {"id": 2, "quantity": 100}]}`,
+	}}
+	app := newTestApp(testCatalog(), llm)
+	converter, err := app.newCurrencyConverter("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := EvalCase{Name: "лоток", Query: "лоток 12 м и гайки", Expected: []EvalExpectedItem{{ID: 1, Quantity: 12}, {ID: 3, Quantity: 5}}}
+
+	result := app.evaluateCase(context.Background(), c, []int{10}, false, converter)
+	if result.Error != "" {
+		t.Fatal(result.Error)
+	}
+	if result.Matched != 1 || !approxEqual(result.Precision, 0.5) || !approxEqual(result.Recall, 0.5) || !approxEqual(result.QuantityAccuracy, 1) {
+		t.Errorf("совпало %d, точность %v, полнота %v, количества %v", result.Matched, result.Precision, result.Recall, result.QuantityAccuracy)
+	}
+	if result.ExpectedCost.Kopecks != 645600 || result.ActualCost.Kopecks != 572600 {
+		t.Errorf("ожидаемая сумма %s, фактическая %s", result.ExpectedCost, result.ActualCost)
+	}
+	if !approxEqual(result.CostError, 73000.0/645600.0) {
+		t.Errorf("ошибка суммы = %v", result.CostError)
+	}
+}
+
+func TestPrintEvalDiffWithoutK(t *testing.T) {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	stdout := os.Stdout
+	os.Stdout = devNull
+	defer func() { os.Stdout = stdout }()
+
+	baseline := EvalRun{K: []int{5}, Summary: EvalSummary{RecallAtK: map[int]float64{5: 0.5}}, Cases: []EvalCaseResult{{Name: "1", Precision: 0.5}}}
+	candidate := EvalRun{Cases: []EvalCaseResult{{Name: "1", Precision: 1}, {Name: "2"}}}
+	printEvalDiff(baseline, candidate)
+	printEvalDiff(candidate, baseline)
+}
@@ -1,12 +1,112 @@
-import { useState } from 'react';
-import { GenerateAndCreateFiles } from '../wailsjs/go/main/App';
+import { useEffect, useRef, useState } from 'react';
+import { CancelGeneration, GetSynonyms, ReimportCatalog, SaveSynonyms, StartGeneration, WaitGeneration } from '../wailsjs/go/main/App';
+import { EventsOn } from '../wailsjs/runtime/runtime';
 import './App.css';
 
+const STAGES = [
+    { key: 'keywords', label: 'Ключевые слова' },
+    { key: 'retrieval', label: 'Поиск по каталогу' },
+    { key: 'planning', label: 'План комплектации' },
+    { key: 'formatting', label: 'Сопоставление' },
+    { key: 'pricing', label: 'Расчет стоимости' },
+    { key: 'rendering', label: 'Формирование DOCX' },
+];
+
+function formatMoney(money) {
+    const amount = (money.kopecks / 100).toLocaleString('ru-RU', { minimumFractionDigits: 2, maximumFractionDigits: 2 });
+    return money.currency === 'RUB' ? `${amount} руб.` : `${amount} ${money.currency}`;
+}
+
+function parseOverrides(text) {
+    return text
+        .split('\n')
+        .map(line => line.trim())
+        .filter(line => line.includes('='))
+        .map(line => {
+            const [product, value] = line.split('=').map(part => part.trim());
+            const number = parseFloat(value.replace('%', '').replace(',', '.'));
+            if (!product || Number.isNaN(number)) {
+                return null;
+            }
+            return value.endsWith('%')
+                ? { product, discountPercent: number }
+                : { product, price: number };
+        })
+        .filter(Boolean);
+}
+
+function formatSynonyms(dictionary) {
+    const groups = (dictionary.groups || []).map(group => group.join(' = '));
+    const abbreviations = Object.entries(dictionary.abbreviations || {})
+        .sort(([a], [b]) => a.localeCompare(b))
+        .map(([abbreviation, expansion]) => `${abbreviation}: ${expansion}`);
+    return [...groups, ...abbreviations].join('\n');
+}
+
+function parseSynonyms(text) {
+    const dictionary = { groups: [], abbreviations: {} };
+    text.split('\n').map(line => line.trim()).filter(Boolean).forEach(line => {
+        if (line.includes('=')) {
+            dictionary.groups.push(line.split('=').map(term => term.trim()).filter(Boolean));
+            return;
+        }
+        const separator = line.indexOf(':');
+        if (separator > 0) {
+            dictionary.abbreviations[line.slice(0, separator).trim()] = line.slice(separator + 1).trim();
+        }
+    });
+    return dictionary;
+}
+
 function App() {
     const [clientQuery, setClientQuery] = useState('Лоток перфорированный 100х100, 12 метров, и 10 гаек М10');
     const [isLoading, setIsLoading] = useState(false);
     const [error, setError] = useState('');
     const [successMessage, setSuccessMessage] = useState('');
+    const [jobId, setJobId] = useState('');
+    const [progress, setProgress] = useState(null);
+    const [keywords, setKeywords] = useState([]);
+    const [plan, setPlan] = useState('');
+    const [customer, setCustomer] = useState('');
+    const [overridesText, setOverridesText] = useState('');
+    const [currency, setCurrency] = useState('');
+    const [catalogDiff, setCatalogDiff] = useState(null);
+    const [isReimporting, setIsReimporting] = useState(false);
+    const [synonymsText, setSynonymsText] = useState(null);
+    const [isSavingSynonyms, setIsSavingSynonyms] = useState(false);
+    const jobIdRef = useRef('');
+
+    useEffect(() => {
+        const offProgress = EventsOn('generation:progress', event => {
+            if (jobIdRef.current && event.jobId !== jobIdRef.current) {
+                return;
+            }
+            setProgress(event);
+            if (event.stage === 'keywords' && Array.isArray(event.artifact)) {
+                setKeywords(event.artifact);
+            }
+            if (event.stage === 'planning' && typeof event.artifact === 'string') {
+                setPlan(event.artifact);
+            }
+        });
+        const offStream = EventsOn('generation:stream', event => {
+            if (jobIdRef.current && event.jobId !== jobIdRef.current) {
+                return;
+            }
+            if (event.stage !== 'planning') {
+                return;
+            }
+            if (event.reset) {
+                setPlan('');
+                return;
+            }
+            setPlan(current => current + event.delta);
+        });
+        return () => {
+            offProgress();
+            offStream();
+        };
+    }, []);
 
     const handleGenerate = () => {
         if (!clientQuery.trim()) {
@@ -18,8 +118,17 @@ function App() {
         setIsLoading(true);
         setError('');
         setSuccessMessage('');
+        setProgress(null);
+        setKeywords([]);
+        setPlan('');
+        jobIdRef.current = '';
 
-        GenerateAndCreateFiles(clientQuery)
+        StartGeneration(clientQuery, { customer: customer.trim(), currency, overrides: parseOverrides(overridesText) })
+            .then(id => {
+                jobIdRef.current = id;
+                setJobId(id);
+                return WaitGeneration(id);
+            })
             .then(base64Data => {
                 const link = document.createElement('a');
                 link.href = `data:application/vnd.openxmlformats-officedocument.wordprocessingml.document;base64,${base64Data}`;
@@ -34,9 +143,59 @@ function App() {
             })
             .finally(() => {
                 setIsLoading(false);
+                setJobId('');
             });
     };
 
+    const handleCancel = () => {
+        if (!jobId) {
+            return;
+        }
+        CancelGeneration(jobId).catch(err => {
+            setError(`Ошибка: ${err}`);
+        });
+    };
+
+    const handleReimport = () => {
+        setIsReimporting(true);
+        setError('');
+        setCatalogDiff(null);
+        ReimportCatalog()
+            .then(diff => setCatalogDiff(diff))
+            .catch(err => {
+                setError(`Ошибка импорта каталога: ${err}`);
+            })
+            .finally(() => setIsReimporting(false));
+    };
+
+    const handleToggleSynonyms = () => {
+        if (synonymsText !== null) {
+            setSynonymsText(null);
+            return;
+        }
+        setError('');
+        GetSynonyms()
+            .then(dictionary => setSynonymsText(formatSynonyms(dictionary)))
+            .catch(err => {
+                setError(`Ошибка загрузки словаря: ${err}`);
+            });
+    };
+
+    const handleSaveSynonyms = () => {
+        setIsSavingSynonyms(true);
+        setError('');
+        setSuccessMessage('');
+        SaveSynonyms(parseSynonyms(synonymsText))
+            .then(dictionary => {
+                setSynonymsText(formatSynonyms(dictionary));
+                setSuccessMessage('Словарь синонимов сохранен.');
+            })
+            .catch(err => {
+                setError(`Ошибка сохранения словаря: ${err}`);
+            })
+            .finally(() => setIsSavingSynonyms(false));
+    };
+
     return (
         <div className="app-container">
             <div className="card">
@@ -56,12 +215,125 @@ function App() {
                     />
                 </div>
 
+                <div className="input-group">
+                    <label htmlFor="customer">Клиент (для персональной скидки)</label>
+                    <input
+                        id="customer"
+                        type="text"
+                        value={customer}
+                        onChange={(e) => setCustomer(e.target.value)}
+                        placeholder="Например: ООО Ромашка"
+                    />
+                </div>
+
+                <div className="input-group">
+                    <label htmlFor="currency">Валюта предложения</label>
+                    <select id="currency" value={currency} onChange={(e) => setCurrency(e.target.value)}>
+                        <option value="">По умолчанию</option>
+                        <option value="RUB">RUB — рубли</option>
+                        <option value="EUR">EUR — евро</option>
+                        <option value="USD">USD — доллары США</option>
+                        <option value="CNY">CNY — юани</option>
+                    </select>
+                </div>
+
+                <div className="input-group">
+                    <label htmlFor="overrides">Ручные цены: артикул или ID = цена либо скидка в %</label>
+                    <textarea
+                        id="overrides"
+                        rows="2"
+                        value={overridesText}
+                        onChange={(e) => setOverridesText(e.target.value)}
+                        placeholder={'A-100 = 1250.50\n15 = 7%'}
+                    />
+                </div>
+
+                {synonymsText !== null && (
+                    <div className="input-group">
+                        <label htmlFor="synonyms">Словарь: синонимы через «=», сокращения через «:»</label>
+                        <textarea
+                            id="synonyms"
+                            rows="6"
+                            value={synonymsText}
+                            onChange={(e) => setSynonymsText(e.target.value)}
+                            placeholder={'лоток = кабельный канал = короб\nлп: лоток перфорированный'}
+                        />
+                        <button className="secondary-button" onClick={handleSaveSynonyms} disabled={isSavingSynonyms}>
+                            {isSavingSynonyms ? 'Сохранение...' : 'Сохранить словарь'}
+                        </button>
+                    </div>
+                )}
+
+                {isLoading && (
+                    <div className="progress-box">
+                        <ol className="stage-list">
+                            {STAGES.map((stage, index) => {
+                                const currentIndex = STAGES.findIndex(s => s.key === progress?.stage);
+                                let state = 'pending';
+                                if (index < currentIndex) {
+                                    state = 'done';
+                                } else if (index === currentIndex) {
+                                    state = 'active';
+                                }
+                                return (
+                                    <li key={stage.key} className={`stage stage-${state}`}>
+                                        {stage.label}
+                                    </li>
+                                );
+                            })}
+                        </ol>
+                        <div className="progress-bar">
+                            <div className="progress-bar-fill" style={{ width: `${progress?.percent ?? 0}%` }} />
+                        </div>
+                        {progress?.message && <div className="progress-message">{progress.message}</div>}
+                        {keywords.length > 0 && (
+                            <div className="progress-artifact">
+                                <span className="progress-artifact-title">Ключевые слова:</span> {keywords.join(', ')}
+                            </div>
+                        )}
+                        {plan && (
+                            <div className="progress-artifact">
+                                <span className="progress-artifact-title">План комплектации:</span>
+                                <pre>{plan}</pre>
+                            </div>
+                        )}
+                    </div>
+                )}
+
                 {error && <div className="error-box">{error}</div>}
                 {successMessage && <div className="success-box">{successMessage}</div>}
+                {catalogDiff && (
+                    <div className="catalog-diff">
+                        <div>
+                            Каталог обновлен: добавлено {catalogDiff.added.length}, удалено {catalogDiff.removed.length},
+                            изменена цена {catalogDiff.repriced.length}, без изменений {catalogDiff.unchanged}.
+                        </div>
+                        {catalogDiff.repriced.length > 0 && (
+                            <ul>
+                                {catalogDiff.repriced.map(change => (
+                                    <li key={change.key}>
+                                        #{change.id} {change.name}: {formatMoney(change.oldPrice)} → {formatMoney(change.newPrice)}
+                                    </li>
+                                ))}
+                            </ul>
+                        )}
+                    </div>
+                )}
 
                 <button onClick={handleGenerate} disabled={isLoading}>
                     {isLoading ? 'Генерация...' : 'Сгенерировать и скачать (.docx)'}
                 </button>
+                {isLoading && (
+                    <button className="secondary-button" onClick={handleCancel} disabled={!jobId}>
+                        Отменить
+                    </button>
+                )}
+                <button className="secondary-button" onClick={handleReimport} disabled={isLoading || isReimporting}>
+                    {isReimporting ? 'Импорт каталога...' : 'Обновить каталог'}
+                </button>
+                <button className="secondary-button" onClick={handleToggleSynonyms} disabled={isLoading}>
+                    {synonymsText === null ? 'Словарь синонимов' : 'Скрыть словарь'}
+                </button>
             </div>
         </div>
     );
@@ -1,4 +1,17 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {main} from '../models';
+
+export function CancelGeneration(arg1:string):Promise<void>;
 
 export function GenerateAndCreateFiles(arg1:string):Promise<string>;
+
+export function GetSynonyms():Promise<main.SynonymDictionary>;
+
+export function ReimportCatalog():Promise<main.CatalogDiff>;
+
+export function SaveSynonyms(arg1:main.SynonymDictionary):Promise<main.SynonymDictionary>;
+
+export function StartGeneration(arg1:string,arg2:main.GenerationOptions):Promise<string>;
+
+export function WaitGeneration(arg1:string):Promise<string>;
@@ -2,6 +2,30 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function CancelGeneration(arg1) {
+  return window['go']['main']['App']['CancelGeneration'](arg1);
+}
+
 export function GenerateAndCreateFiles(arg1) {
   return window['go']['main']['App']['GenerateAndCreateFiles'](arg1);
 }
+
+export function GetSynonyms() {
+  return window['go']['main']['App']['GetSynonyms']();
+}
+
+export function ReimportCatalog() {
+  return window['go']['main']['App']['ReimportCatalog']();
+}
+
+export function SaveSynonyms(arg1) {
+  return window['go']['main']['App']['SaveSynonyms'](arg1);
+}
+
+export function StartGeneration(arg1, arg2) {
+  return window['go']['main']['App']['StartGeneration'](arg1, arg2);
+}
+
+export function WaitGeneration(arg1) {
+  return window['go']['main']['App']['WaitGeneration'](arg1);
+}
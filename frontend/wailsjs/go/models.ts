@@ -0,0 +1,211 @@
+export namespace main {
+	
+	export class PriceOverride {
+	    product: string;
+	    price?: number;
+	    discountPercent?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PriceOverride(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.product = source["product"];
+	        this.price = source["price"];
+	        this.discountPercent = source["discountPercent"];
+	    }
+	}
+	export class GenerationOptions {
+	    customer: string;
+	    currency: string;
+	    overrides: PriceOverride[];
+	
+	    static createFrom(source: any = {}) {
+	        return new GenerationOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.customer = source["customer"];
+	        this.currency = source["currency"];
+	        this.overrides = this.convertValues(source["overrides"], PriceOverride);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Money {
+	    kopecks: number;
+	    currency: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Money(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kopecks = source["kopecks"];
+	        this.currency = source["currency"];
+	    }
+	}
+	export class PriceChange {
+	    id: number;
+	    key: string;
+	    name: string;
+	    oldPrice: Money;
+	    newPrice: Money;
+	
+	    static createFrom(source: any = {}) {
+	        return new PriceChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.key = source["key"];
+	        this.name = source["name"];
+	        this.oldPrice = this.convertValues(source["oldPrice"], Money);
+	        this.newPrice = this.convertValues(source["newPrice"], Money);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Product {
+	    id: number;
+	    key?: string;
+	    externalId?: string;
+	    name: string;
+	    article?: string;
+	    unit?: string;
+	    category?: string;
+	    price: Money;
+	    cost?: Money;
+	    stock?: number;
+	    packSize?: number;
+	    vatRate?: number;
+	    sourceLine?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Product(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.key = source["key"];
+	        this.externalId = source["externalId"];
+	        this.name = source["name"];
+	        this.article = source["article"];
+	        this.unit = source["unit"];
+	        this.category = source["category"];
+	        this.price = this.convertValues(source["price"], Money);
+	        this.cost = this.convertValues(source["cost"], Money);
+	        this.stock = source["stock"];
+	        this.packSize = source["packSize"];
+	        this.vatRate = source["vatRate"];
+	        this.sourceLine = source["sourceLine"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CatalogDiff {
+	    added: Product[];
+	    removed: Product[];
+	    repriced: PriceChange[];
+	    unchanged: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CatalogDiff(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.added = this.convertValues(source["added"], Product);
+	        this.removed = this.convertValues(source["removed"], Product);
+	        this.repriced = this.convertValues(source["repriced"], PriceChange);
+	        this.unchanged = source["unchanged"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SynonymDictionary {
+	    groups: string[][];
+	    abbreviations: Record<string, string>;
+	
+	    static createFrom(source: any = {}) {
+	        return new SynonymDictionary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.groups = source["groups"];
+	        this.abbreviations = source["abbreviations"];
+	    }
+	}
+
+}
+
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"log"
+	"sort"
+	"strings"
+)
+
+const (
+	fuzzyMinTermLength = 4
+	fuzzyMaxCandidates = 3
+)
+
+type weightedTerm struct {
+	term   string
+	weight float64
+}
+
+type trigramIndex struct {
+	terms    []string
+	trigrams map[string][]int
+}
+
+func termTrigrams(term string) []string {
+	runes := []rune("$" + term + "$")
+	if len(runes) < 3 {
+		return nil
+	}
+	seen := make(map[string]bool, len(runes))
+	var trigrams []string
+	for i := 0; i+3 <= len(runes); i++ {
+		trigram := string(runes[i : i+3])
+		if !seen[trigram] {
+			seen[trigram] = true
+			trigrams = append(trigrams, trigram)
+		}
+	}
+	return trigrams
+}
+
+func buildTrigramIndex(vocabulary []string) *trigramIndex {
+	index := &trigramIndex{terms: vocabulary, trigrams: make(map[string][]int)}
+	for id, term := range vocabulary {
+		for _, trigram := range termTrigrams(term) {
+			index.trigrams[trigram] = append(index.trigrams[trigram], id)
+		}
+	}
+	return index
+}
+
+func maxEditDistance(term string) int {
+	if len([]rune(term)) <= 5 {
+		return 1
+	}
+	return 2
+}
+
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	rows := make([][]int, len(s)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(t)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(s)][len(t)]
+}
+
+func (idx *trigramIndex) similar(term string) []weightedTerm {
+	length := len([]rune(term))
+	if idx == nil || length < fuzzyMinTermLength || strings.ContainsRune(term, ':') {
+		return nil
+	}
+	maxDistance := maxEditDistance(term)
+	shared := make(map[int]int)
+	for _, trigram := range termTrigrams(term) {
+		for _, id := range idx.trigrams[trigram] {
+			shared[id]++
+		}
+	}
+	var matches []weightedTerm
+	distances := make(map[string]int)
+	for id, count := range shared {
+		candidate := idx.terms[id]
+		diff := len([]rune(candidate)) - length
+		if count == 0 || diff > maxDistance || -diff > maxDistance {
+			continue
+		}
+		distance := editDistance(term, candidate)
+		if distance == 0 || distance > maxDistance {
+			continue
+		}
+		distances[candidate] = distance
+		matches = append(matches, weightedTerm{term: candidate, weight: 1 / float64(1+distance)})
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if distances[matches[i].term] != distances[matches[j].term] {
+			return distances[matches[i].term] < distances[matches[j].term]
+		}
+		return matches[i].term < matches[j].term
+	})
+	if len(matches) > fuzzyMaxCandidates {
+		matches = matches[:fuzzyMaxCandidates]
+	}
+	return matches
+}
+
+func (idx *productIndex) expandQuery(terms []string) []weightedTerm {
+	var expanded []weightedTerm
+	for _, term := range terms {
+		if len(idx.postings[term]) > 0 {
+			expanded = append(expanded, weightedTerm{term: term, weight: 1})
+			continue
+		}
+		matches := idx.fuzzy.similar(term)
+		if len(matches) == 0 {
+			continue
+		}
+		corrections := make([]string, len(matches))
+		for i, match := range matches {
+			corrections[i] = match.term
+		}
+		log.Printf("Нечеткий поиск: '%s' -> %s", term, strings.Join(corrections, ", "))
+		expanded = append(expanded, matches...)
+	}
+	return expanded
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrigramIndexSimilar(t *testing.T) {
+	index := buildTrigramIndex([]string{"лоток", "лотки", "крышка", "кронштейн", "гайка", "шайба", "перфорированный"})
+	tests := []struct {
+		term string
+		want []weightedTerm
+	}{
+		{"крыжка", []weightedTerm{{term: "крышка", weight: 0.5}}},
+		{"лотак", []weightedTerm{{term: "лоток", weight: 0.5}}},
+		{"гаика", []weightedTerm{{term: "гайка", weight: 0.5}}},
+		{"перфорированый", []weightedTerm{{term: "перфорированный", weight: 0.5}}},
+		{"крнштейн", []weightedTerm{{term: "кронштейн", weight: 0.5}}},
+		{"гайка", nil},
+		{"гай", nil},
+		{"dim:100x100", nil},
+		{"болт", nil},
+	}
+	for _, tt := range tests {
+		if got := index.similar(tt.term); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("similar(%q) = %+v, ожидалось %+v", tt.term, got, tt.want)
+		}
+	}
+}
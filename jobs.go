@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+var errGenerationCanceled = errors.New("генерация отменена пользователем")
+
+const finishedJobTTL = 10 * time.Minute
+
+type generationJob struct {
+	id         string
+	query      string
+	options    GenerationOptions
+	cancel     context.CancelFunc
+	done       chan struct{}
+	result     string
+	err        error
+	finishedAt time.Time
+}
+
+var jobCounter atomic.Int64
+
+func (a *App) StartGeneration(clientRequest string, options GenerationOptions) (string, error) {
+	if clientRequest == "" {
+		return "", fmt.Errorf("пустой запрос клиента")
+	}
+	if err := validateOverrides(options.Overrides); err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithCancel(a.baseContext())
+	job := &generationJob{
+		id:      fmt.Sprintf("%d-%d", time.Now().Unix(), jobCounter.Add(1)),
+		query:   clientRequest,
+		options: options,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+
+	a.jobsMutex.Lock()
+	a.pruneFinishedJobsLocked()
+	if a.jobs == nil {
+		a.jobs = make(map[string]*generationJob)
+	}
+	a.jobs[job.id] = job
+	a.jobsMutex.Unlock()
+
+	log.Printf("Запущена генерация %s для запроса: %s", job.id, clientRequest)
+	go a.runGenerationJob(withProgressReporter(ctx, jobReporter{app: a, jobID: job.id}), job)
+	return job.id, nil
+}
+
+func (a *App) runGenerationJob(ctx context.Context, job *generationJob) {
+	defer job.cancel()
+	result, err := a.generateProposal(ctx, job.query, job.options)
+	if err != nil && ctx.Err() != nil && errors.Is(ctx.Err(), context.Canceled) {
+		err = errGenerationCanceled
+		log.Printf("Генерация %s отменена.", job.id)
+	}
+	switch {
+	case err == nil:
+		reportProgress(ctx, ProgressDone, 100, "Готово", nil)
+	case errors.Is(err, errGenerationCanceled):
+		reportProgress(ctx, ProgressCanceled, 100, err.Error(), nil)
+	default:
+		reportProgress(ctx, ProgressFailed, 100, err.Error(), nil)
+	}
+
+	a.jobsMutex.Lock()
+	job.result = base64.StdEncoding.EncodeToString(result)
+	job.err = err
+	job.finishedAt = time.Now()
+	a.pruneFinishedJobsLocked()
+	a.jobsMutex.Unlock()
+	close(job.done)
+}
+
+func (a *App) CancelGeneration(jobID string) error {
+	a.jobsMutex.Lock()
+	a.pruneFinishedJobsLocked()
+	job, ok := a.jobs[jobID]
+	a.jobsMutex.Unlock()
+	if !ok {
+		return fmt.Errorf("задача генерации '%s' не найдена", jobID)
+	}
+	job.cancel()
+	return nil
+}
+
+func (a *App) WaitGeneration(jobID string) (string, error) {
+	a.jobsMutex.Lock()
+	a.pruneFinishedJobsLocked()
+	job, ok := a.jobs[jobID]
+	a.jobsMutex.Unlock()
+	if !ok {
+		return "", fmt.Errorf("задача генерации '%s' не найдена", jobID)
+	}
+	<-job.done
+	return job.result, job.err
+}
+
+func (a *App) pruneFinishedJobsLocked() {
+	for id, job := range a.jobs {
+		if !job.finishedAt.IsZero() && time.Since(job.finishedAt) > finishedJobTTL {
+			delete(a.jobs, id)
+		}
+	}
+}
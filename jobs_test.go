@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type blockingLLMProvider struct {
+	started chan struct{}
+	once    sync.Once
+}
+
+func (b *blockingLLMProvider) Name() string {
+	return "blocking"
+}
+
+func (b *blockingLLMProvider) Chat(ctx context.Context, messages []ChatMessage, opts ChatOptions) (ChatCompletion, error) {
+	b.once.Do(func() { close(b.started) })
+	<-ctx.Done()
+	return ChatCompletion{}, ctx.Err()
+}
+
+type stageRecorder struct {
+	mu     sync.Mutex
+	stages []string
+}
+
+func (r *stageRecorder) sink(eventName string, data ...interface{}) {
+	if eventName != generationProgressEvent || len(data) == 0 {
+		return
+	}
+	if progress, ok := data[0].(GenerationProgress); ok {
+		r.mu.Lock()
+		r.stages = append(r.stages, progress.Stage)
+		r.mu.Unlock()
+	}
+}
+
+func (r *stageRecorder) last() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.stages) == 0 {
+		return ""
+	}
+	return r.stages[len(r.stages)-1]
+}
+
+func TestCancelGeneration(t *testing.T) {
+	chdirTemp(t)
+	llm := &blockingLLMProvider{started: make(chan struct{})}
+	app := newTestApp(testCatalog(), llm)
+	recorder := &stageRecorder{}
+	app.eventSink = recorder.sink
+
+	id, err := app.StartGeneration("лоток 100х100 12 метров", GenerationOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-llm.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("генерация не дошла до вызова LLM")
+	}
+	if err := app.CancelGeneration(id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.WaitGeneration(id); !errors.Is(err, errGenerationCanceled) {
+		t.Fatalf("ошибка = %v, ожидалась отмена", err)
+	}
+	if recorder.stages[0] != ProgressLoading || recorder.last() != ProgressCanceled {
+		t.Errorf("этапы = %v", recorder.stages)
+	}
+	if err := app.CancelGeneration("нет-такой"); err == nil {
+		t.Error("отмена неизвестной задачи должна возвращать ошибку")
+	}
+}
+
+func TestGenerationFailureStatus(t *testing.T) {
+	chdirTemp(t)
+	app := newTestApp(testCatalog(), &fakeLLMProvider{name: "fake", err: errors.New("нет сети")})
+	recorder := &stageRecorder{}
+	app.eventSink = recorder.sink
+
+	id, err := app.StartGeneration("гайки М10", GenerationOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.WaitGeneration(id); err == nil || errors.Is(err, errGenerationCanceled) {
+		t.Fatalf("ошибка = %v, ожидалась ошибка генерации", err)
+	}
+	if recorder.last() != ProgressFailed {
+		t.Errorf("этапы = %v", recorder.stages)
+	}
+
+	if _, err := app.StartGeneration("", GenerationOptions{}); err == nil {
+		t.Error("пустой запрос должен отклоняться")
+	}
+	discount := 100.0
+	if _, err := app.StartGeneration("гайки", GenerationOptions{Overrides: []PriceOverride{{Product: "2", DiscountPercent: &discount}}}); err == nil {
+		t.Error("скидка 100% должна отклоняться")
+	}
+}
+
+func TestPruneFinishedJobs(t *testing.T) {
+	app := newTestApp(testCatalog(), &fakeLLMProvider{name: "fake"})
+	expired := &generationJob{id: "expired", cancel: func() {}, done: make(chan struct{}), finishedAt: time.Now().Add(-finishedJobTTL - time.Minute)}
+	recent := &generationJob{id: "recent", cancel: func() {}, done: make(chan struct{}), finishedAt: time.Now().Add(-time.Minute)}
+	running := &generationJob{id: "running", cancel: func() {}, done: make(chan struct{})}
+	close(expired.done)
+	close(recent.done)
+	app.jobs = map[string]*generationJob{"expired": expired, "recent": recent, "running": running}
+
+	if err := app.CancelGeneration("running"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := app.jobs["expired"]; ok {
+		t.Error("устаревшая задача должна удаляться при обращении к задачам")
+	}
+	if _, ok := app.jobs["recent"]; !ok {
+		t.Error("недавно завершенная задача удалена раньше срока")
+	}
+	if _, ok := app.jobs["running"]; !ok {
+		t.Error("незавершенная задача не должна удаляться")
+	}
+
+	recent.finishedAt = time.Now().Add(-finishedJobTTL - time.Second)
+	if _, err := app.WaitGeneration("recent"); err == nil {
+		t.Error("ожидание устаревшей задачи должно возвращать ошибку")
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+type ChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ChatOptions struct {
+	Temperature float32
+	MaxTokens   int
+	JSONMode    bool
+	Timeout     time.Duration
+	OnStream    func(StreamDelta)
+}
+
+type StreamDelta struct {
+	Provider string
+	Content  string
+	Reset    bool
+}
+
+type ChatCompletion struct {
+	Content  string
+	Provider string
+}
+
+type LLMProvider interface {
+	Name() string
+	Chat(ctx context.Context, messages []ChatMessage, opts ChatOptions) (ChatCompletion, error)
+}
+
+type LLMRequestError struct {
+	Provider   string
+	StatusCode int
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *LLMRequestError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *LLMRequestError) Unwrap() error {
+	return e.Err
+}
+
+func (e *LLMRequestError) Temporary() bool {
+	return e.StatusCode == 0 || e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+func newLLMNetworkError(provider string, err error) error {
+	return &LLMRequestError{Provider: provider, Err: err}
+}
+
+func newLLMStatusError(provider string, resp *resty.Response, err error) error {
+	return &LLMRequestError{
+		Provider:   provider,
+		StatusCode: resp.StatusCode(),
+		RetryAfter: parseRetryAfter(resp.Header().Get("Retry-After")),
+		Err:        err,
+	}
+}
+
+func isTemporaryLLMError(err error) bool {
+	var reqErr *LLMRequestError
+	return errors.As(err, &reqErr) && reqErr.Temporary()
+}
+
+type LLMProviderFactory func(cfg Config, client *resty.Client) (LLMProvider, error)
+
+var llmProviderFactories = map[string]LLMProviderFactory{}
+
+func registerLLMProvider(name string, factory LLMProviderFactory) {
+	key := strings.ToLower(name)
+	if _, exists := llmProviderFactories[key]; exists {
+		panic(fmt.Sprintf("LLM-провайдер '%s' уже зарегистрирован", name))
+	}
+	llmProviderFactories[key] = factory
+}
+
+func registeredLLMProviders() []string {
+	names := make([]string, 0, len(llmProviderFactories))
+	for name := range llmProviderFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newLLMProvider(name string, cfg Config, client *resty.Client) (LLMProvider, error) {
+	factory, ok := llmProviderFactories[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("неизвестный LLM-провайдер '%s', доступные: %s", name, strings.Join(registeredLLMProviders(), ", "))
+	}
+	return factory(cfg, client)
+}
+
+func (cfg Config) providerName() string {
+	if cfg.Provider != "" {
+		return cfg.Provider
+	}
+	if cfg.UseGigaChat {
+		return "gigachat"
+	}
+	return "ollama"
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+type providerHealth struct {
+	failures      int
+	cooldownUntil time.Time
+	lastError     string
+}
+
+type fallbackProvider struct {
+	providers        []LLMProvider
+	cooldown         time.Duration
+	failureThreshold int
+	mu               sync.Mutex
+	health           map[string]*providerHealth
+}
+
+func newLLMProviderChain(cfg Config, client *resty.Client) (LLMProvider, error) {
+	names := []string{cfg.providerName()}
+	for _, name := range cfg.Fallback.Providers {
+		duplicate := false
+		for _, existing := range names {
+			if strings.EqualFold(existing, name) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			names = append(names, name)
+		}
+	}
+	providers := make([]LLMProvider, 0, len(names))
+	var errs []error
+	for _, name := range names {
+		provider, err := newLLMProvider(name, cfg, client)
+		if err != nil {
+			log.Printf("ПРЕДУПРЕЖДЕНИЕ: провайдер %s исключен из цепочки: %v", name, err)
+			errs = append(errs, err)
+			continue
+		}
+		providers = append(providers, withRetry(provider, cfg.LLM.Retry))
+	}
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("не удалось инициализировать ни одного LLM-провайдера: %w", errors.Join(errs...))
+	}
+	if len(providers) == 1 {
+		return providers[0], nil
+	}
+	cooldown := time.Duration(cfg.Fallback.CooldownSeconds) * time.Second
+	if cooldown <= 0 {
+		cooldown = time.Minute
+	}
+	threshold := cfg.Fallback.FailureThreshold
+	if threshold <= 0 {
+		threshold = 1
+	}
+	return &fallbackProvider{
+		providers:        providers,
+		cooldown:         cooldown,
+		failureThreshold: threshold,
+		health:           make(map[string]*providerHealth),
+	}, nil
+}
+
+func (f *fallbackProvider) Name() string {
+	names := make([]string, len(f.providers))
+	for i, p := range f.providers {
+		names[i] = p.Name()
+	}
+	return strings.Join(names, " → ")
+}
+
+func (f *fallbackProvider) Chat(ctx context.Context, messages []ChatMessage, opts ChatOptions) (ChatCompletion, error) {
+	var errs []error
+	resetStream := resettableStream(&opts)
+	for _, provider := range f.orderedProviders() {
+		if err := ctx.Err(); err != nil {
+			return ChatCompletion{}, err
+		}
+		resetStream()
+		completion, err := provider.Chat(ctx, messages, opts)
+		if err == nil {
+			f.markSuccess(provider.Name())
+			return completion, nil
+		}
+		if ctx.Err() != nil || !isTemporaryLLMError(err) {
+			return ChatCompletion{}, err
+		}
+		f.markFailure(provider.Name(), err)
+		log.Printf("ПРЕДУПРЕЖДЕНИЕ: провайдер %s недоступен, переключаюсь на следующий. Ошибка: %v", provider.Name(), err)
+		errs = append(errs, err)
+	}
+	return ChatCompletion{}, fmt.Errorf("все LLM-провайдеры недоступны: %w", errors.Join(errs...))
+}
+
+func (f *fallbackProvider) orderedProviders() []LLMProvider {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	var available, cooling []LLMProvider
+	for _, p := range f.providers {
+		if h, ok := f.health[p.Name()]; ok && now.Before(h.cooldownUntil) {
+			cooling = append(cooling, p)
+			continue
+		}
+		available = append(available, p)
+	}
+	return append(available, cooling...)
+}
+
+func (f *fallbackProvider) markSuccess(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.health, name)
+}
+
+func (f *fallbackProvider) markFailure(name string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	h, ok := f.health[name]
+	if !ok {
+		h = &providerHealth{}
+		f.health[name] = h
+	}
+	h.failures++
+	h.lastError = err.Error()
+	if h.failures >= f.failureThreshold {
+		h.cooldownUntil = time.Now().Add(f.cooldown)
+		log.Printf("Провайдер %s исключен из очереди до %s после %d ошибок подряд.", name, h.cooldownUntil.Format("15:04:05"), h.failures)
+	}
+}
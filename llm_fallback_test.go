@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+func newTestFallback(providers ...LLMProvider) *fallbackProvider {
+	return &fallbackProvider{
+		providers:        providers,
+		cooldown:         time.Minute,
+		failureThreshold: 1,
+		health:           make(map[string]*providerHealth),
+	}
+}
+
+func chatOnce(t *testing.T, provider LLMProvider) (ChatCompletion, error) {
+	t.Helper()
+	return provider.Chat(context.Background(), []ChatMessage{{Role: "user", Content: "гайки"}}, ChatOptions{})
+}
+
+func TestFallbackProviderOrder(t *testing.T) {
+	primary := &fakeLLMProvider{name: "primary", err: &LLMRequestError{StatusCode: http.StatusServiceUnavailable, Err: errors.New("перегружен")}}
+	secondary := &fakeLLMProvider{name: "secondary", replies: []string{"ответ 1", "ответ 2"}}
+	chain := newTestFallback(primary, secondary)
+
+	completion, err := chatOnce(t, chain)
+	if err != nil || completion.Provider != "secondary" || completion.Content != "ответ 1" {
+		t.Fatalf("ответ = %+v, %v", completion, err)
+	}
+	if len(primary.prompts) != 1 || len(secondary.prompts) != 1 {
+		t.Fatalf("вызовов primary %d, secondary %d", len(primary.prompts), len(secondary.prompts))
+	}
+
+	if _, err := chatOnce(t, chain); err != nil {
+		t.Fatal(err)
+	}
+	if len(primary.prompts) != 1 {
+		t.Errorf("провайдер на паузе не должен вызываться первым: вызовов %d", len(primary.prompts))
+	}
+	if got := chain.orderedProviders(); got[0].Name() != "secondary" || got[1].Name() != "primary" {
+		t.Errorf("порядок при паузе = %s, %s", got[0].Name(), got[1].Name())
+	}
+
+	chain.health["primary"].cooldownUntil = time.Now().Add(-time.Second)
+	if got := chain.orderedProviders(); got[0].Name() != "primary" {
+		t.Errorf("после окончания паузы первым должен быть primary, получено %s", got[0].Name())
+	}
+}
+
+func TestFallbackProviderErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		primaryErr    error
+		wantSecondary int
+		wantErr       string
+	}{
+		{"сетевая ошибка переключает", newLLMNetworkError("primary", errors.New("connection refused")), 1, "все LLM-провайдеры недоступны"},
+		{"429 переключает", &LLMRequestError{StatusCode: http.StatusTooManyRequests, Err: errors.New("лимит")}, 1, "все LLM-провайдеры недоступны"},
+		{"400 не переключает", &LLMRequestError{StatusCode: http.StatusBadRequest, Err: errors.New("плохой запрос")}, 0, "плохой запрос"},
+		{"ошибка без статуса не переключает", errors.New("пустой ответ"), 0, "пустой ответ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &fakeLLMProvider{name: "primary", err: tt.primaryErr}
+			secondary := &fakeLLMProvider{name: "secondary", err: newLLMNetworkError("secondary", errors.New("тоже нет сети"))}
+			_, err := chatOnce(t, newTestFallback(primary, secondary))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ошибка = %v, ожидалось содержание %q", err, tt.wantErr)
+			}
+			if len(secondary.prompts) != tt.wantSecondary {
+				t.Errorf("вызовов secondary %d, ожидалось %d", len(secondary.prompts), tt.wantSecondary)
+			}
+		})
+	}
+}
+
+func TestFallbackProviderFailureThreshold(t *testing.T) {
+	primary := &fakeLLMProvider{name: "primary", err: newLLMNetworkError("primary", errors.New("нет сети"))}
+	secondary := &fakeLLMProvider{name: "secondary", replies: []string{"1", "2", "3"}}
+	chain := newTestFallback(primary, secondary)
+	chain.failureThreshold = 2
+
+	for i := 0; i < 3; i++ {
+		if _, err := chatOnce(t, chain); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(primary.prompts) != 2 {
+		t.Errorf("primary должен уйти на паузу после 2 ошибок, вызовов %d", len(primary.prompts))
+	}
+
+	primary.err = nil
+	primary.replies = []string{"снова в строю"}
+	chain.health["primary"].cooldownUntil = time.Time{}
+	completion, err := chatOnce(t, chain)
+	if err != nil || completion.Provider != "primary" {
+		t.Fatalf("ответ = %+v, %v", completion, err)
+	}
+	if _, ok := chain.health["primary"]; ok {
+		t.Error("успешный вызов должен сбрасывать счетчик ошибок")
+	}
+}
+
+func TestNewLLMProviderChainSkipsBrokenProviders(t *testing.T) {
+	cfg := Config{Provider: "ollama", Ollama: OllamaConfig{BaseURL: "http://localhost:11434", Model: "llama3"}, Fallback: FallbackConfig{Providers: []string{"нет-такого", "ollama"}}}
+	provider, err := newLLMProviderChain(cfg, resty.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if provider.Name() != "ollama" {
+		t.Errorf("цепочка = %s, ожидался только ollama", provider.Name())
+	}
+
+	cfg.Provider = "нет-такого"
+	cfg.Fallback.Providers = []string{"ollama"}
+	if provider, err = newLLMProviderChain(cfg, resty.New()); err != nil || provider.Name() != "ollama" {
+		t.Errorf("при неисправном основном провайдере цепочка = %v, %v", provider, err)
+	}
+
+	cfg.Fallback.Providers = nil
+	if _, err := newLLMProviderChain(cfg, resty.New()); err == nil {
+		t.Error("без единого рабочего провайдера ожидалась ошибка")
+	}
+}
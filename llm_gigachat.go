@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+type GigaChatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []ChatMessage `json:"messages"`
+	Temperature float32       `json:"temperature"`
+	MaxTokens   int           `json:"max_tokens,omitempty"`
+	Stream      bool          `json:"stream,omitempty"`
+}
+type GigaChatResponseChoice struct {
+	Message ChatMessage `json:"message"`
+}
+type GigaChatResponse struct {
+	Choices []GigaChatResponseChoice `json:"choices"`
+}
+
+type gigaChatProvider struct {
+	config         GigaChatConfig
+	httpClient     *resty.Client
+	gigaToken      string
+	tokenExpiresAt time.Time
+	tokenMutex     sync.Mutex
+}
+
+func init() {
+	registerLLMProvider("gigachat", func(cfg Config, client *resty.Client) (LLMProvider, error) {
+		if cfg.GigaChat.APIKey == "" {
+			return nil, fmt.Errorf("не задан apiKey для GigaChat в config.json")
+		}
+		return &gigaChatProvider{config: cfg.GigaChat, httpClient: client}, nil
+	})
+}
+
+func (p *gigaChatProvider) Name() string {
+	return "gigachat"
+}
+
+func (p *gigaChatProvider) Chat(ctx context.Context, messages []ChatMessage, opts ChatOptions) (ChatCompletion, error) {
+	token, err := p.getAccessToken(ctx)
+	if err != nil {
+		return ChatCompletion{}, err
+	}
+	requestBody := GigaChatRequest{
+		Model:       p.config.Model,
+		Messages:    messages,
+		Temperature: opts.Temperature,
+		MaxTokens:   opts.MaxTokens,
+	}
+	if opts.OnStream != nil {
+		requestBody.Stream = true
+		return p.chatStream(ctx, token, requestBody, opts.OnStream)
+	}
+	resp, err := p.httpClient.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/json").
+		SetHeader("Accept", "application/json").
+		SetAuthToken(token).
+		SetBody(requestBody).
+		Post("https://gigachat.devices.sberbank.ru/api/v1/chat/completions")
+
+	if err != nil {
+		return ChatCompletion{}, newLLMNetworkError(p.Name(), fmt.Errorf("сетевая ошибка при вызове GigaChat: %w", err))
+	}
+	log.Printf("RAW RESPONSE BODY FROM GIGACHAT:\n%s\n", resp.String())
+	if resp.IsError() {
+		return ChatCompletion{}, newLLMStatusError(p.Name(), resp, fmt.Errorf("ошибка от API GigaChat: %s - %s", resp.Status(), resp.String()))
+	}
+	var gigaResponse GigaChatResponse
+	if err := json.Unmarshal(resp.Body(), &gigaResponse); err != nil {
+		return ChatCompletion{}, fmt.Errorf("ошибка парсинга ответа GigaChat: %w", err)
+	}
+	if len(gigaResponse.Choices) == 0 {
+		return ChatCompletion{}, fmt.Errorf("GigaChat вернул пустой ответ")
+	}
+	return ChatCompletion{Content: gigaResponse.Choices[0].Message.Content, Provider: p.Name()}, nil
+}
+
+func (p *gigaChatProvider) chatStream(ctx context.Context, token string, requestBody GigaChatRequest, onStream func(StreamDelta)) (ChatCompletion, error) {
+	resp, err := p.httpClient.R().
+		SetContext(ctx).
+		SetDoNotParseResponse(true).
+		SetHeader("Content-Type", "application/json").
+		SetHeader("Accept", "text/event-stream").
+		SetAuthToken(token).
+		SetBody(requestBody).
+		Post("https://gigachat.devices.sberbank.ru/api/v1/chat/completions")
+
+	if err != nil {
+		return ChatCompletion{}, newLLMNetworkError(p.Name(), fmt.Errorf("сетевая ошибка при вызове GigaChat: %w", err))
+	}
+	if resp.IsError() {
+		return ChatCompletion{}, newLLMStatusError(p.Name(), resp, fmt.Errorf("ошибка от API GigaChat: %s - %s", resp.Status(), readRawErrorBody(resp)))
+	}
+	body := resp.RawBody()
+	defer body.Close()
+	content, err := readSSEChatStream(body, p.Name(), onStream)
+	if err != nil {
+		return ChatCompletion{}, newLLMStreamError(p.Name(), "обрыв потокового ответа GigaChat", err)
+	}
+	if content == "" {
+		return ChatCompletion{}, fmt.Errorf("GigaChat вернул пустой ответ")
+	}
+	return ChatCompletion{Content: content, Provider: p.Name()}, nil
+}
+
+func (p *gigaChatProvider) getAccessToken(ctx context.Context) (string, error) {
+	p.tokenMutex.Lock()
+	defer p.tokenMutex.Unlock()
+	if p.gigaToken != "" && time.Now().Before(p.tokenExpiresAt) {
+		return p.gigaToken, nil
+	}
+	log.Println("Токен GigaChat истек или отсутствует. Получение нового токена...")
+	resp, err := p.httpClient.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/x-www-form-urlencoded").
+		SetHeader("Accept", "application/json").
+		SetHeader("RqUID", "a1a2a3a4-a5a6-a7a8-a9a0-a1a2a3a4a5a6").
+		SetHeader("Authorization", "Basic "+p.config.APIKey).
+		SetFormData(map[string]string{
+			"scope": "GIGACHAT_API_PERS",
+		}).
+		Post("https://ngw.devices.sberbank.ru:9443/api/v2/oauth")
+	if err != nil {
+		return "", newLLMNetworkError(p.Name(), fmt.Errorf("ошибка при запросе токена: %w", err))
+	}
+	if resp.IsError() {
+		return "", newLLMStatusError(p.Name(), resp, fmt.Errorf("ошибка от API при получении токена: %s - %s", resp.Status(), resp.String()))
+	}
+	var tokenResponse struct {
+		AccessToken string `json:"access_token"`
+		ExpiresAt   int64  `json:"expires_at"`
+	}
+	if err := json.Unmarshal(resp.Body(), &tokenResponse); err != nil {
+		return "", fmt.Errorf("ошибка парсинга ответа с токеном: %w", err)
+	}
+	p.gigaToken = tokenResponse.AccessToken
+	p.tokenExpiresAt = time.Unix(tokenResponse.ExpiresAt/1000, 0).Add(-1 * time.Minute)
+	log.Println("Новый токен GigaChat успешно получен.")
+	return p.gigaToken, nil
+}
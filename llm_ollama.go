@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+)
+
+type OllamaOptions struct {
+	Temperature float32 `json:"temperature"`
+	NumPredict  int     `json:"num_predict,omitempty"`
+}
+type OllamaRequest struct {
+	Model    string        `json:"model"`
+	Messages []ChatMessage `json:"messages"`
+	Stream   bool          `json:"stream"`
+	Format   string        `json:"format,omitempty"`
+	Options  OllamaOptions `json:"options"`
+}
+type OllamaResponse struct {
+	Message ChatMessage `json:"message"`
+	Done    bool        `json:"done"`
+	Error   string      `json:"error,omitempty"`
+}
+
+type ollamaProvider struct {
+	config     OllamaConfig
+	httpClient *resty.Client
+}
+
+func init() {
+	registerLLMProvider("ollama", func(cfg Config, client *resty.Client) (LLMProvider, error) {
+		if cfg.Ollama.BaseURL == "" {
+			return nil, fmt.Errorf("не задан baseURL для Ollama в config.json")
+		}
+		return &ollamaProvider{config: cfg.Ollama, httpClient: client}, nil
+	})
+}
+
+func (p *ollamaProvider) Name() string {
+	return "ollama"
+}
+
+func (p *ollamaProvider) Chat(ctx context.Context, messages []ChatMessage, opts ChatOptions) (ChatCompletion, error) {
+	requestBody := OllamaRequest{
+		Model:    p.config.Model,
+		Messages: messages,
+		Stream:   false,
+		Options: OllamaOptions{
+			Temperature: opts.Temperature,
+			NumPredict:  opts.MaxTokens,
+		},
+	}
+	if opts.JSONMode {
+		requestBody.Format = "json"
+	}
+	if opts.OnStream != nil {
+		requestBody.Stream = true
+		return p.chatStream(ctx, requestBody, opts.OnStream)
+	}
+	apiURL := p.config.BaseURL + "/api/chat"
+	resp, err := p.httpClient.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/json").
+		SetBody(requestBody).
+		Post(apiURL)
+
+	if err != nil {
+		return ChatCompletion{}, newLLMNetworkError(p.Name(), fmt.Errorf("сетевая ошибка при вызове Ollama: %w", err))
+	}
+	log.Printf("RAW RESPONSE BODY FROM OLLAMA:\n%s\n", resp.String())
+	if resp.IsError() {
+		return ChatCompletion{}, newLLMStatusError(p.Name(), resp, fmt.Errorf("ошибка от API Ollama: %s - %s", resp.Status(), resp.String()))
+	}
+	var ollamaResponse OllamaResponse
+	if err := json.Unmarshal(resp.Body(), &ollamaResponse); err != nil {
+		return ChatCompletion{}, fmt.Errorf("ошибка парсинга ответа Ollama: %w", err)
+	}
+	return ChatCompletion{Content: ollamaResponse.Message.Content, Provider: p.Name()}, nil
+}
+
+func (p *ollamaProvider) chatStream(ctx context.Context, requestBody OllamaRequest, onStream func(StreamDelta)) (ChatCompletion, error) {
+	resp, err := p.httpClient.R().
+		SetContext(ctx).
+		SetDoNotParseResponse(true).
+		SetHeader("Content-Type", "application/json").
+		SetBody(requestBody).
+		Post(p.config.BaseURL + "/api/chat")
+
+	if err != nil {
+		return ChatCompletion{}, newLLMNetworkError(p.Name(), fmt.Errorf("сетевая ошибка при вызове Ollama: %w", err))
+	}
+	if resp.IsError() {
+		return ChatCompletion{}, newLLMStatusError(p.Name(), resp, fmt.Errorf("ошибка от API Ollama: %s - %s", resp.Status(), readRawErrorBody(resp)))
+	}
+	body := resp.RawBody()
+	defer body.Close()
+	content, err := readOllamaChatStream(body, p.Name(), onStream)
+	if err != nil {
+		return ChatCompletion{}, newLLMStreamError(p.Name(), "обрыв потокового ответа Ollama", err)
+	}
+	if content == "" {
+		return ChatCompletion{}, fmt.Errorf("Ollama вернула пустой ответ")
+	}
+	return ChatCompletion{Content: content, Provider: p.Name()}, nil
+}
+
+func readOllamaChatStream(body io.Reader, provider string, onStream func(StreamDelta)) (string, error) {
+	var content strings.Builder
+	err := scanStreamLines(body, func(line string) (bool, error) {
+		var chunk OllamaResponse
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			return false, &streamParseError{provider: provider, err: err}
+		}
+		if chunk.Error != "" {
+			return false, fmt.Errorf("ошибка от API Ollama: %s", chunk.Error)
+		}
+		if chunk.Message.Content != "" {
+			content.WriteString(chunk.Message.Content)
+			onStream(StreamDelta{Provider: provider, Content: chunk.Message.Content})
+		}
+		return chunk.Done, nil
+	})
+	return content.String(), err
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+)
+
+type OpenAIResponseFormat struct {
+	Type string `json:"type"`
+}
+type OpenAIChatRequest struct {
+	Model          string                `json:"model"`
+	Messages       []ChatMessage         `json:"messages"`
+	Temperature    float32               `json:"temperature"`
+	MaxTokens      int                   `json:"max_tokens,omitempty"`
+	Stream         bool                  `json:"stream"`
+	ResponseFormat *OpenAIResponseFormat `json:"response_format,omitempty"`
+}
+type OpenAIChatChoice struct {
+	Message      ChatMessage `json:"message"`
+	FinishReason string      `json:"finish_reason"`
+}
+type OpenAIChatResponse struct {
+	Choices []OpenAIChatChoice `json:"choices"`
+}
+
+type openAIProvider struct {
+	config     OpenAIConfig
+	httpClient *resty.Client
+}
+
+func init() {
+	registerLLMProvider("openai", func(cfg Config, client *resty.Client) (LLMProvider, error) {
+		if cfg.OpenAI.BaseURL == "" {
+			return nil, fmt.Errorf("не задан baseURL для OpenAI-совместимого API в config.json")
+		}
+		if cfg.OpenAI.Model == "" {
+			return nil, fmt.Errorf("не задана модель для OpenAI-совместимого API в config.json")
+		}
+		return &openAIProvider{config: cfg.OpenAI, httpClient: client}, nil
+	})
+}
+
+func (p *openAIProvider) Name() string {
+	return "openai"
+}
+
+func (p *openAIProvider) endpoint() string {
+	base := strings.TrimRight(p.config.BaseURL, "/")
+	if strings.HasSuffix(base, "/v1") {
+		return base + "/chat/completions"
+	}
+	return base + "/v1/chat/completions"
+}
+
+func (p *openAIProvider) Chat(ctx context.Context, messages []ChatMessage, opts ChatOptions) (ChatCompletion, error) {
+	requestBody := OpenAIChatRequest{
+		Model:       p.config.Model,
+		Messages:    messages,
+		Temperature: opts.Temperature,
+		MaxTokens:   opts.MaxTokens,
+		Stream:      false,
+	}
+	if opts.JSONMode && p.config.JSONMode {
+		requestBody.ResponseFormat = &OpenAIResponseFormat{Type: "json_object"}
+	}
+	if opts.OnStream != nil {
+		requestBody.Stream = true
+		return p.chatStream(ctx, requestBody, opts.OnStream)
+	}
+	req := p.httpClient.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/json").
+		SetHeader("Accept", "application/json").
+		SetBody(requestBody)
+	if p.config.APIKey != "" {
+		req.SetAuthToken(p.config.APIKey)
+	}
+	resp, err := req.Post(p.endpoint())
+
+	if err != nil {
+		return ChatCompletion{}, newLLMNetworkError(p.Name(), fmt.Errorf("сетевая ошибка при вызове OpenAI-совместимого API: %w", err))
+	}
+	log.Printf("RAW RESPONSE BODY FROM OPENAI-COMPATIBLE API:\n%s\n", resp.String())
+	if resp.IsError() {
+		return ChatCompletion{}, newLLMStatusError(p.Name(), resp, fmt.Errorf("ошибка от OpenAI-совместимого API: %s - %s", resp.Status(), resp.String()))
+	}
+	var openAIResponse OpenAIChatResponse
+	if err := json.Unmarshal(resp.Body(), &openAIResponse); err != nil {
+		return ChatCompletion{}, fmt.Errorf("ошибка парсинга ответа OpenAI-совместимого API: %w", err)
+	}
+	if len(openAIResponse.Choices) == 0 {
+		return ChatCompletion{}, fmt.Errorf("OpenAI-совместимый API вернул пустой ответ")
+	}
+	return ChatCompletion{Content: openAIResponse.Choices[0].Message.Content, Provider: p.Name()}, nil
+}
+
+func (p *openAIProvider) chatStream(ctx context.Context, requestBody OpenAIChatRequest, onStream func(StreamDelta)) (ChatCompletion, error) {
+	req := p.httpClient.R().
+		SetContext(ctx).
+		SetDoNotParseResponse(true).
+		SetHeader("Content-Type", "application/json").
+		SetHeader("Accept", "text/event-stream").
+		SetBody(requestBody)
+	if p.config.APIKey != "" {
+		req.SetAuthToken(p.config.APIKey)
+	}
+	resp, err := req.Post(p.endpoint())
+
+	if err != nil {
+		return ChatCompletion{}, newLLMNetworkError(p.Name(), fmt.Errorf("сетевая ошибка при вызове OpenAI-совместимого API: %w", err))
+	}
+	if resp.IsError() {
+		return ChatCompletion{}, newLLMStatusError(p.Name(), resp, fmt.Errorf("ошибка от OpenAI-совместимого API: %s - %s", resp.Status(), readRawErrorBody(resp)))
+	}
+	body := resp.RawBody()
+	defer body.Close()
+	content, err := readSSEChatStream(body, p.Name(), onStream)
+	if err != nil {
+		return ChatCompletion{}, newLLMStreamError(p.Name(), "обрыв потокового ответа OpenAI-совместимого API", err)
+	}
+	if content == "" {
+		return ChatCompletion{}, fmt.Errorf("OpenAI-совместимый API вернул пустой ответ")
+	}
+	return ChatCompletion{Content: content, Provider: p.Name()}, nil
+}
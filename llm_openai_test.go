@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+func TestOpenAIProviderEndpoint(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		want    string
+	}{
+		{"http://localhost:8000", "http://localhost:8000/v1/chat/completions"},
+		{"http://localhost:8000/", "http://localhost:8000/v1/chat/completions"},
+		{"http://localhost:8000/v1", "http://localhost:8000/v1/chat/completions"},
+		{"http://localhost:8000/v1/", "http://localhost:8000/v1/chat/completions"},
+		{"https://api.example.com/openai/v1", "https://api.example.com/openai/v1/chat/completions"},
+		{"https://api.example.com/openai", "https://api.example.com/openai/v1/chat/completions"},
+	}
+	for _, tt := range tests {
+		p := &openAIProvider{config: OpenAIConfig{BaseURL: tt.baseURL}}
+		if got := p.endpoint(); got != tt.want {
+			t.Errorf("endpoint(%q) = %q, ожидалось %q", tt.baseURL, got, tt.want)
+		}
+	}
+}
+
+type capturedOpenAIRequest struct {
+	path          string
+	authorization string
+	body          map[string]interface{}
+}
+
+func newOpenAITestServer(t *testing.T, status int, reply string, captured *capturedOpenAIRequest) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		captured.path = r.URL.Path
+		captured.authorization = r.Header.Get("Authorization")
+		data, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &captured.body); err != nil {
+			t.Errorf("тело запроса не JSON: %s", data)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "7")
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(reply))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOpenAIProviderChatRequest(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        OpenAIConfig
+		opts          ChatOptions
+		wantAuth      string
+		wantFormat    bool
+		wantMaxTokens bool
+	}{
+		{"без ключа и JSON-режима", OpenAIConfig{Model: "local-model"}, ChatOptions{Temperature: 0.2}, "", false, false},
+		{"с ключом и JSON-режимом", OpenAIConfig{Model: "gpt", APIKey: "sk-test", JSONMode: true}, ChatOptions{JSONMode: true, MaxTokens: 512}, "Bearer sk-test", true, true},
+		{"JSON-режим выключен в настройках", OpenAIConfig{Model: "gpt"}, ChatOptions{JSONMode: true}, "", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var captured capturedOpenAIRequest
+			server := newOpenAITestServer(t, http.StatusOK, `{"choices":[{"message":{"role":"assistant","content":"{\"keywords\":[]}"},"finish_reason":"stop"}]}`, &captured)
+			tt.config.BaseURL = server.URL + "/v1"
+			p := &openAIProvider{config: tt.config, httpClient: resty.New()}
+
+			completion, err := p.Chat(context.Background(), []ChatMessage{{Role: "system", Content: "ты помощник"}, {Role: "user", Content: "гайки"}}, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if completion.Content != `{"keywords":[]}` || completion.Provider != "openai" {
+				t.Errorf("ответ = %+v", completion)
+			}
+			if captured.path != "/v1/chat/completions" || captured.authorization != tt.wantAuth {
+				t.Errorf("путь %q, авторизация %q", captured.path, captured.authorization)
+			}
+			body := captured.body
+			if body["model"] != tt.config.Model || body["stream"] != false {
+				t.Errorf("тело запроса = %v", body)
+			}
+			if messages, ok := body["messages"].([]interface{}); !ok || len(messages) != 2 {
+				t.Errorf("сообщения = %v", body["messages"])
+			}
+			if _, ok := body["response_format"]; ok != tt.wantFormat {
+				t.Errorf("response_format = %v, ожидалось наличие: %v", body["response_format"], tt.wantFormat)
+			}
+			if _, ok := body["max_tokens"]; ok != tt.wantMaxTokens {
+				t.Errorf("max_tokens = %v, ожидалось наличие: %v", body["max_tokens"], tt.wantMaxTokens)
+			}
+		})
+	}
+}
+
+func TestOpenAIProviderChatErrors(t *testing.T) {
+	var captured capturedOpenAIRequest
+	server := newOpenAITestServer(t, http.StatusTooManyRequests, `{"error":{"message":"rate limit"}}`, &captured)
+	p := &openAIProvider{config: OpenAIConfig{BaseURL: server.URL, Model: "gpt"}, httpClient: resty.New()}
+	_, err := p.Chat(context.Background(), []ChatMessage{{Role: "user", Content: "гайки"}}, ChatOptions{})
+	var reqErr *LLMRequestError
+	if !errors.As(err, &reqErr) || reqErr.StatusCode != http.StatusTooManyRequests || reqErr.RetryAfter != 7*time.Second || !reqErr.Temporary() {
+		t.Fatalf("ошибка = %#v", err)
+	}
+
+	empty := newOpenAITestServer(t, http.StatusOK, `{"choices":[]}`, &captured)
+	p.config.BaseURL = empty.URL
+	if _, err := p.Chat(context.Background(), []ChatMessage{{Role: "user", Content: "гайки"}}, ChatOptions{}); err == nil || !strings.Contains(err.Error(), "пустой ответ") {
+		t.Errorf("ошибка = %v, ожидался пустой ответ", err)
+	}
+}
+
+func TestOpenAIProviderFactory(t *testing.T) {
+	tests := []struct {
+		config  OpenAIConfig
+		wantErr bool
+	}{
+		{OpenAIConfig{BaseURL: "http://localhost:8000/v1", Model: "local-model"}, false},
+		{OpenAIConfig{Model: "local-model"}, true},
+		{OpenAIConfig{BaseURL: "http://localhost:8000/v1"}, true},
+	}
+	for _, tt := range tests {
+		if _, err := newLLMProvider("OpenAI", Config{OpenAI: tt.config}, resty.New()); (err != nil) != tt.wantErr {
+			t.Errorf("%+v: ошибка = %v, ожидалась: %v", tt.config, err, tt.wantErr)
+		}
+	}
+}
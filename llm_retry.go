@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type LLMStage string
+
+const (
+	StageKeywords       LLMStage = "keywords"
+	StagePlanning       LLMStage = "planning"
+	StageFormatting     LLMStage = "formatting"
+	StageCatalogParsing LLMStage = "catalog_parsing"
+)
+
+const (
+	defaultLLMTimeout    = 5 * time.Minute
+	defaultRetryAttempts = 3
+)
+
+var defaultRetryableStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+func (t TimeoutConfig) forStage(stage LLMStage) time.Duration {
+	var seconds int
+	switch stage {
+	case StageKeywords:
+		seconds = t.KeywordsSeconds
+	case StagePlanning:
+		seconds = t.PlanningSeconds
+	case StageFormatting:
+		seconds = t.FormattingSeconds
+	case StageCatalogParsing:
+		seconds = t.CatalogParsingSeconds
+	}
+	if seconds <= 0 {
+		seconds = t.DefaultSeconds
+	}
+	if seconds <= 0 {
+		return defaultLLMTimeout
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+type retryingProvider struct {
+	inner             LLMProvider
+	maxAttempts       int
+	baseDelay         time.Duration
+	maxDelay          time.Duration
+	retryableStatuses map[int]bool
+}
+
+func withRetry(inner LLMProvider, cfg RetryConfig) LLMProvider {
+	attempts := cfg.MaxAttempts
+	if attempts <= 0 {
+		attempts = defaultRetryAttempts
+	}
+	baseDelay := time.Duration(cfg.BaseDelayMs) * time.Millisecond
+	if baseDelay <= 0 {
+		baseDelay = 500 * time.Millisecond
+	}
+	maxDelay := time.Duration(cfg.MaxDelayMs) * time.Millisecond
+	if maxDelay <= 0 {
+		maxDelay = 30 * time.Second
+	}
+	statuses := cfg.RetryableStatuses
+	if len(statuses) == 0 {
+		statuses = defaultRetryableStatuses
+	}
+	retryable := make(map[int]bool, len(statuses))
+	for _, status := range statuses {
+		retryable[status] = true
+	}
+	return &retryingProvider{
+		inner:             inner,
+		maxAttempts:       attempts,
+		baseDelay:         baseDelay,
+		maxDelay:          maxDelay,
+		retryableStatuses: retryable,
+	}
+}
+
+func (r *retryingProvider) Name() string {
+	return r.inner.Name()
+}
+
+func (r *retryingProvider) Chat(ctx context.Context, messages []ChatMessage, opts ChatOptions) (ChatCompletion, error) {
+	var lastErr error
+	resetStream := resettableStream(&opts)
+	for attempt := 1; attempt <= r.maxAttempts; attempt++ {
+		resetStream()
+		completion, err := r.attempt(ctx, messages, opts)
+		if err == nil {
+			return completion, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil || !r.shouldRetry(err) || attempt == r.maxAttempts {
+			break
+		}
+		delay := r.backoff(attempt, err)
+		log.Printf("Попытка %d/%d вызова %s не удалась, повтор через %s. Ошибка: %v", attempt, r.maxAttempts, r.inner.Name(), delay.Round(time.Millisecond), err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ChatCompletion{}, ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if ctx.Err() != nil {
+		return ChatCompletion{}, ctx.Err()
+	}
+	return ChatCompletion{}, lastErr
+}
+
+func (r *retryingProvider) attempt(ctx context.Context, messages []ChatMessage, opts ChatOptions) (ChatCompletion, error) {
+	if opts.Timeout <= 0 {
+		return r.inner.Chat(ctx, messages, opts)
+	}
+	callCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	completion, err := r.inner.Chat(callCtx, messages, opts)
+	if err != nil && ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		return ChatCompletion{}, newLLMNetworkError(r.inner.Name(), fmt.Errorf("превышен таймаут %s при вызове %s: %w", opts.Timeout, r.inner.Name(), err))
+	}
+	return completion, err
+}
+
+func (r *retryingProvider) shouldRetry(err error) bool {
+	var reqErr *LLMRequestError
+	if !errors.As(err, &reqErr) {
+		return false
+	}
+	return reqErr.StatusCode == 0 || r.retryableStatuses[reqErr.StatusCode]
+}
+
+func (r *retryingProvider) backoff(attempt int, err error) time.Duration {
+	var reqErr *LLMRequestError
+	if errors.As(err, &reqErr) && reqErr.RetryAfter > 0 {
+		if reqErr.RetryAfter > r.maxDelay {
+			return r.maxDelay
+		}
+		return reqErr.RetryAfter
+	}
+	delay := r.baseDelay << (attempt - 1)
+	if delay <= 0 || delay > r.maxDelay {
+		delay = r.maxDelay
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	future := time.Now().Add(90 * time.Second).UTC().Format(http.TimeFormat)
+	past := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+	tests := []struct {
+		value    string
+		min, max time.Duration
+	}{
+		{"", 0, 0},
+		{"  ", 0, 0},
+		{"5", 5 * time.Second, 5 * time.Second},
+		{" 120 ", 2 * time.Minute, 2 * time.Minute},
+		{"0", 0, 0},
+		{"-3", 0, 0},
+		{"скоро", 0, 0},
+		{future, 80 * time.Second, 90 * time.Second},
+		{past, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value); got < tt.min || got > tt.max {
+			t.Errorf("parseRetryAfter(%q) = %s, ожидалось от %s до %s", tt.value, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestRetryingProviderBackoff(t *testing.T) {
+	provider := withRetry(&fakeLLMProvider{name: "fake"}, RetryConfig{BaseDelayMs: 100, MaxDelayMs: 1000}).(*retryingProvider)
+	plain := newLLMNetworkError("fake", errors.New("нет сети"))
+	tests := []struct {
+		attempt int
+		err     error
+		want    time.Duration
+	}{
+		{1, plain, 100 * time.Millisecond},
+		{2, plain, 200 * time.Millisecond},
+		{4, plain, 800 * time.Millisecond},
+		{5, plain, time.Second},
+		{70, plain, time.Second},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 50; i++ {
+			if got := provider.backoff(tt.attempt, tt.err); got < tt.want/2 || got > tt.want {
+				t.Fatalf("попытка %d: задержка %s вне [%s, %s]", tt.attempt, got, tt.want/2, tt.want)
+			}
+		}
+	}
+
+	limited := &LLMRequestError{Provider: "fake", StatusCode: 429, RetryAfter: 300 * time.Millisecond, Err: errors.New("лимит")}
+	if got := provider.backoff(3, limited); got != 300*time.Millisecond {
+		t.Errorf("Retry-After 300ms: задержка %s", got)
+	}
+	limited.RetryAfter = time.Minute
+	if got := provider.backoff(1, limited); got != time.Second {
+		t.Errorf("Retry-After больше maxDelay должен ограничиваться: задержка %s", got)
+	}
+}
+
+func TestRetryingProviderShouldRetry(t *testing.T) {
+	provider := withRetry(&fakeLLMProvider{name: "fake"}, RetryConfig{}).(*retryingProvider)
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"сетевая ошибка", newLLMNetworkError("fake", errors.New("connection reset")), true},
+		{"429", &LLMRequestError{StatusCode: http.StatusTooManyRequests, Err: errors.New("лимит")}, true},
+		{"500", &LLMRequestError{StatusCode: http.StatusInternalServerError, Err: errors.New("сбой")}, true},
+		{"503", &LLMRequestError{StatusCode: http.StatusServiceUnavailable, Err: errors.New("сбой")}, true},
+		{"400", &LLMRequestError{StatusCode: http.StatusBadRequest, Err: errors.New("плохой запрос")}, false},
+		{"401", &LLMRequestError{StatusCode: http.StatusUnauthorized, Err: errors.New("нет доступа")}, false},
+		{"404", &LLMRequestError{StatusCode: http.StatusNotFound, Err: errors.New("нет модели")}, false},
+		{"обычная ошибка", errors.New("пустой ответ"), false},
+	}
+	for _, tt := range tests {
+		if got := provider.shouldRetry(tt.err); got != tt.want {
+			t.Errorf("%s: shouldRetry = %v, ожидалось %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRetryingProviderChat(t *testing.T) {
+	cfg := RetryConfig{MaxAttempts: 3, BaseDelayMs: 1, MaxDelayMs: 2}
+	temporary := &fakeLLMProvider{name: "fake", err: &LLMRequestError{StatusCode: http.StatusBadGateway, Err: errors.New("сбой шлюза")}}
+	if _, err := withRetry(temporary, cfg).Chat(context.Background(), []ChatMessage{{Role: "user", Content: "привет"}}, ChatOptions{}); err == nil {
+		t.Fatal("ожидалась ошибка после исчерпания попыток")
+	}
+	if len(temporary.prompts) != 3 {
+		t.Errorf("временная ошибка: попыток %d, ожидалось 3", len(temporary.prompts))
+	}
+
+	permanent := &fakeLLMProvider{name: "fake", err: &LLMRequestError{StatusCode: http.StatusBadRequest, Err: errors.New("плохой запрос")}}
+	if _, err := withRetry(permanent, cfg).Chat(context.Background(), []ChatMessage{{Role: "user", Content: "привет"}}, ChatOptions{}); err == nil {
+		t.Fatal("ожидалась ошибка")
+	}
+	if len(permanent.prompts) != 1 {
+		t.Errorf("постоянная ошибка не должна повторяться: попыток %d", len(permanent.prompts))
+	}
+}
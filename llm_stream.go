@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const maxStreamLineSize = 1024 * 1024
+
+type chatStreamChunk struct {
+	Choices []struct {
+		Delta        ChatMessage `json:"delta"`
+		FinishReason string      `json:"finish_reason"`
+	} `json:"choices"`
+}
+
+type streamParseError struct {
+	provider string
+	err      error
+}
+
+func (e *streamParseError) Error() string {
+	return fmt.Sprintf("ошибка парсинга потокового ответа %s: %v", e.provider, e.err)
+}
+
+func (e *streamParseError) Unwrap() error {
+	return e.err
+}
+
+func newLLMStreamError(provider, description string, err error) error {
+	var parseErr *streamParseError
+	if errors.As(err, &parseErr) {
+		return err
+	}
+	return newLLMNetworkError(provider, fmt.Errorf("%s: %w", description, err))
+}
+
+func readRawErrorBody(resp *resty.Response) string {
+	body := resp.RawBody()
+	if body == nil {
+		return ""
+	}
+	defer body.Close()
+	data, _ := io.ReadAll(io.LimitReader(body, maxStreamLineSize))
+	return string(data)
+}
+
+func scanStreamLines(body io.Reader, onLine func(line string) (bool, error)) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), maxStreamLineSize)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		stop, err := onLine(line)
+		if err != nil {
+			return err
+		}
+		if stop {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+func readSSEChatStream(body io.Reader, provider string, onStream func(StreamDelta)) (string, error) {
+	var content strings.Builder
+	err := scanStreamLines(body, func(line string) (bool, error) {
+		if !strings.HasPrefix(line, "data:") {
+			return false, nil
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			return true, nil
+		}
+		var chunk chatStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return false, &streamParseError{provider: provider, err: err}
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+			content.WriteString(choice.Delta.Content)
+			onStream(StreamDelta{Provider: provider, Content: choice.Delta.Content})
+		}
+		return false, nil
+	})
+	return content.String(), err
+}
+
+func resettableStream(opts *ChatOptions) func() {
+	onStream := opts.OnStream
+	if onStream == nil {
+		return func() {}
+	}
+	streamed := false
+	opts.OnStream = func(delta StreamDelta) {
+		streamed = true
+		onStream(delta)
+	}
+	return func() {
+		if streamed {
+			streamed = false
+			onStream(StreamDelta{Reset: true})
+		}
+	}
+}
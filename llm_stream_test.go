@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+)
+
+func collectDeltas() (*[]string, func(StreamDelta)) {
+	var deltas []string
+	return &deltas, func(delta StreamDelta) { deltas = append(deltas, delta.Content) }
+}
+
+func TestReadSSEChatStream(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		want       string
+		wantDeltas int
+		wantParse  bool
+	}{
+		{
+			name:       "чанки и [DONE]",
+			body:       "data: {\"choices\":[{\"delta\":{\"content\":\"Лоток\"}}]}\n\ndata: {\"choices\":[{\"delta\":{\"content\":\" 100х100\"}}]}\n\ndata: [DONE]\n\ndata: {\"choices\":[{\"delta\":{\"content\":\"после конца\"}}]}\n",
+			want:       "Лоток 100х100",
+			wantDeltas: 2,
+		},
+		{
+			name:       "служебные строки и пустые дельты",
+			body:       ": keep-alive\nevent: message\ndata: {\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\ndata:{\"choices\":[{\"delta\":{\"content\":\"ок\"},\"finish_reason\":\"stop\"}]}\n",
+			want:       "ок",
+			wantDeltas: 1,
+		},
+		{
+			name: "пустой поток",
+			body: "",
+		},
+		{
+			name:       "битый чанк",
+			body:       "data: {\"choices\":[{\"delta\":{\"content\":\"нач\"}}]}\ndata: {\"choices\": [\n",
+			want:       "нач",
+			wantDeltas: 1,
+			wantParse:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deltas, onStream := collectDeltas()
+			content, err := readSSEChatStream(strings.NewReader(tt.body), "openai", onStream)
+			var parseErr *streamParseError
+			if tt.wantParse != errors.As(err, &parseErr) || (!tt.wantParse && err != nil) {
+				t.Fatalf("ошибка = %v, ожидалась ошибка парсинга: %v", err, tt.wantParse)
+			}
+			if content != tt.want || len(*deltas) != tt.wantDeltas {
+				t.Errorf("содержимое %q, дельт %d; ожидалось %q, %d", content, len(*deltas), tt.want, tt.wantDeltas)
+			}
+		})
+	}
+}
+
+func TestReadOllamaChatStream(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		want      string
+		wantErr   string
+		wantParse bool
+	}{
+		{
+			name: "чанки до done",
+			body: "{\"message\":{\"content\":\"Гайка\"},\"done\":false}\n{\"message\":{\"content\":\" М10\"},\"done\":false}\n{\"message\":{\"content\":\"\"},\"done\":true}\n{\"message\":{\"content\":\"лишнее\"}}\n",
+			want: "Гайка М10",
+		},
+		{
+			name: "пустой поток",
+			body: "\n\n",
+		},
+		{
+			name:      "битый чанк",
+			body:      "{\"message\":{\"content\":\"Гай\"}}\n{\"message\":\n",
+			want:      "Гай",
+			wantParse: true,
+		},
+		{
+			name:    "ошибка в потоке",
+			body:    "{\"error\":\"model not found\"}\n",
+			wantErr: "model not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, onStream := collectDeltas()
+			content, err := readOllamaChatStream(strings.NewReader(tt.body), "ollama", onStream)
+			var parseErr *streamParseError
+			switch {
+			case tt.wantParse:
+				if !errors.As(err, &parseErr) {
+					t.Fatalf("ожидалась ошибка парсинга, получено %v", err)
+				}
+			case tt.wantErr != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ошибка = %v, ожидалось %q", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatal(err)
+			}
+			if content != tt.want {
+				t.Errorf("содержимое %q, ожидалось %q", content, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewLLMStreamError(t *testing.T) {
+	parseErr := &streamParseError{provider: "openai", err: errors.New("unexpected EOF")}
+	if err := newLLMStreamError("openai", "обрыв", parseErr); err != parseErr || isTemporaryLLMError(err) {
+		t.Errorf("ошибка парсинга должна остаться постоянной: %v", err)
+	}
+	if err := newLLMStreamError("openai", "обрыв", errors.New("connection reset")); !isTemporaryLLMError(err) || !strings.Contains(err.Error(), "обрыв") {
+		t.Errorf("обрыв соединения должен быть временной ошибкой: %v", err)
+	}
+}
+
+func TestStreamingProvidersRejectEmptyContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		provider func(url string) LLMProvider
+	}{
+		{"ollama", "{\"message\":{\"content\":\"\"},\"done\":true}\n", func(url string) LLMProvider {
+			return &ollamaProvider{config: OllamaConfig{BaseURL: url, Model: "llama3"}, httpClient: resty.New()}
+		}},
+		{"openai", "data: [DONE]\n", func(url string) LLMProvider {
+			return &openAIProvider{config: OpenAIConfig{BaseURL: url, Model: "local-model"}, httpClient: resty.New()}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+			_, onStream := collectDeltas()
+			_, err := tt.provider(server.URL).Chat(context.Background(), []ChatMessage{{Role: "user", Content: "гайки"}}, ChatOptions{OnStream: onStream})
+			if err == nil || !strings.Contains(err.Error(), "пустой ответ") {
+				t.Errorf("ошибка = %v, ожидался пустой ответ", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const defaultCurrency = "RUB"
+
+type Money struct {
+	Kopecks  int64  `json:"kopecks"`
+	Currency string `json:"currency"`
+}
+
+var currencyAliases = map[string]string{
+	"руб": "RUB", "руб.": "RUB", "рубль": "RUB", "₽": "RUB", "rur": "RUB", "643": "RUB",
+	"usd": "USD", "$": "USD", "840": "USD",
+	"eur": "EUR", "€": "EUR", "978": "EUR",
+	"cny": "CNY", "юань": "CNY", "156": "CNY",
+}
+
+var currencySymbols = map[string]string{
+	"RUB": "руб.",
+	"USD": "$",
+	"EUR": "€",
+	"CNY": "¥",
+}
+
+func normalizeCurrency(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return defaultCurrency
+	}
+	if code, ok := currencyAliases[strings.ToLower(value)]; ok {
+		return code
+	}
+	return strings.ToUpper(value)
+}
+
+func moneyFromFloat(value float64, currency string) Money {
+	return Money{Kopecks: int64(math.Round(value * 100)), Currency: normalizeCurrency(currency)}
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var amount float64
+	if err := json.Unmarshal(data, &amount); err == nil {
+		*m = moneyFromFloat(amount, defaultCurrency)
+		return nil
+	}
+	type plainMoney Money
+	var value plainMoney
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("некорректная денежная сумма %s: %w", string(data), err)
+	}
+	value.Currency = normalizeCurrency(value.Currency)
+	*m = Money(value)
+	return nil
+}
+
+func (m Money) Add(other Money) Money {
+	if m.Currency == "" {
+		m.Currency = other.Currency
+	}
+	m.Kopecks += other.Kopecks
+	return m
+}
+
+func (m Money) Sub(other Money) Money {
+	m.Kopecks -= other.Kopecks
+	return m
+}
+
+func (m Money) checkCurrency(other Money) error {
+	if m.Currency != "" && other.Currency != "" && m.Currency != other.Currency {
+		return fmt.Errorf("нельзя складывать суммы в разных валютах: %s и %s", m, other)
+	}
+	return nil
+}
+
+func (m Money) checkedAdd(other Money) (Money, error) {
+	if err := m.checkCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return m.Add(other), nil
+}
+
+func (m Money) checkedSub(other Money) (Money, error) {
+	if err := m.checkCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return m.Sub(other), nil
+}
+
+func (m Money) Mul(quantity int) Money {
+	m.Kopecks *= int64(quantity)
+	return m
+}
+
+func (m Money) IsZero() bool {
+	return m.Kopecks == 0
+}
+
+func (m Money) Float() float64 {
+	return float64(m.Kopecks) / 100
+}
+
+func (m Money) ratio(numerator, denominator int64) Money {
+	value := m.Kopecks * numerator
+	if value >= 0 {
+		m.Kopecks = (value + denominator/2) / denominator
+	} else {
+		m.Kopecks = (value - denominator/2) / denominator
+	}
+	return m
+}
+
+func (m Money) Amount() string {
+	kopecks := m.Kopecks
+	sign := ""
+	if kopecks < 0 {
+		sign = "-"
+		kopecks = -kopecks
+	}
+	rubles := strconv.FormatInt(kopecks/100, 10)
+	var grouped strings.Builder
+	for i, digit := range rubles {
+		if i > 0 && (len(rubles)-i)%3 == 0 {
+			grouped.WriteRune('\u00a0')
+		}
+		grouped.WriteRune(digit)
+	}
+	return fmt.Sprintf("%s%s,%02d", sign, grouped.String(), kopecks%100)
+}
+
+func (m Money) String() string {
+	currency := m.Currency
+	if currency == "" {
+		currency = defaultCurrency
+	}
+	if symbol, ok := currencySymbols[currency]; ok {
+		currency = symbol
+	}
+	return m.Amount() + " " + currency
+}
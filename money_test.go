@@ -0,0 +1,61 @@
+package main
+
+import "testing"
+
+func TestMoneyRatio(t *testing.T) {
+	tests := []struct {
+		kopecks                int64
+		numerator, denominator int64
+		want                   int64
+	}{
+		{10000, 20, 120, 1667},
+		{10000, 20, 100, 2000},
+		{1, 1, 2, 1},
+		{-1, 1, 2, -1},
+		{-10000, 20, 120, -1667},
+		{333, 1, 3, 111},
+		{0, 10, 110, 0},
+	}
+	for _, tt := range tests {
+		got := Money{Kopecks: tt.kopecks, Currency: "RUB"}.ratio(tt.numerator, tt.denominator)
+		if got.Kopecks != tt.want || got.Currency != "RUB" {
+			t.Errorf("%d * %d/%d = %v, ожидалось %d", tt.kopecks, tt.numerator, tt.denominator, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyAmount(t *testing.T) {
+	tests := []struct {
+		kopecks int64
+		want    string
+	}{
+		{0, "0,00"},
+		{5, "0,05"},
+		{123456, "1\u00a0234,56"},
+		{-100000000, "-1\u00a0000\u00a0000,00"},
+	}
+	for _, tt := range tests {
+		if got := (Money{Kopecks: tt.kopecks}).Amount(); got != tt.want {
+			t.Errorf("Amount(%d) = %q, ожидалось %q", tt.kopecks, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyCheckedArithmetic(t *testing.T) {
+	rub := Money{Kopecks: 1000, Currency: "RUB"}
+	if sum, err := rub.checkedAdd(Money{Kopecks: 250, Currency: "RUB"}); err != nil || sum.Kopecks != 1250 {
+		t.Errorf("1000 + 250 = %v, %v", sum, err)
+	}
+	if sum, err := (Money{}).checkedAdd(rub); err != nil || sum.Currency != "RUB" {
+		t.Errorf("пустая сумма должна принять валюту слагаемого: %v, %v", sum, err)
+	}
+	if diff, err := rub.checkedSub(Money{Kopecks: 1500, Currency: "RUB"}); err != nil || diff.Kopecks != -500 {
+		t.Errorf("1000 - 1500 = %v, %v", diff, err)
+	}
+	if _, err := rub.checkedAdd(Money{Kopecks: 100, Currency: "USD"}); err == nil {
+		t.Error("сложение RUB и USD должно вернуть ошибку")
+	}
+	if _, err := rub.checkedSub(Money{Kopecks: 100, Currency: "EUR"}); err == nil {
+		t.Error("вычитание EUR из RUB должно вернуть ошибку")
+	}
+}
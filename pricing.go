@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type PricingConfig struct {
+	Customers        []CustomerDiscount `json:"customers"`
+	VolumeBreaks     []VolumeBreak      `json:"volumeBreaks"`
+	MinMarginPercent float64            `json:"minMarginPercent"`
+}
+
+type CustomerDiscount struct {
+	Name            string  `json:"name"`
+	DiscountPercent float64 `json:"discountPercent"`
+}
+
+type VolumeBreak struct {
+	Product         string  `json:"product"`
+	Category        string  `json:"category"`
+	MinQuantity     int     `json:"minQuantity"`
+	DiscountPercent float64 `json:"discountPercent"`
+}
+
+type GenerationOptions struct {
+	Customer  string          `json:"customer"`
+	Currency  string          `json:"currency"`
+	Overrides []PriceOverride `json:"overrides"`
+}
+
+type PriceOverride struct {
+	Product         string   `json:"product"`
+	Price           *float64 `json:"price,omitempty"`
+	DiscountPercent *float64 `json:"discountPercent,omitempty"`
+}
+
+type priceDecision struct {
+	price    Money
+	discount float64
+	rule     string
+}
+
+func productMatches(product Product, selector string) bool {
+	selector = strings.TrimSpace(selector)
+	if selector == "" {
+		return false
+	}
+	if id, err := strconv.Atoi(selector); err == nil && id == product.ID {
+		return true
+	}
+	return selector == product.Key || (product.Article != "" && strings.EqualFold(selector, product.Article))
+}
+
+func (b VolumeBreak) appliesTo(product Product, quantity int) bool {
+	if quantity < b.MinQuantity {
+		return false
+	}
+	if b.Product != "" {
+		return productMatches(product, b.Product)
+	}
+	if b.Category != "" {
+		return strings.Contains(strings.ToLower(product.Category), strings.ToLower(b.Category))
+	}
+	return true
+}
+
+func (c PricingConfig) customer(name string) (CustomerDiscount, bool) {
+	name = strings.TrimSpace(name)
+	for _, customer := range c.Customers {
+		if name != "" && strings.EqualFold(strings.TrimSpace(customer.Name), name) {
+			return customer, true
+		}
+	}
+	return CustomerDiscount{}, false
+}
+
+func (o PriceOverride) validate() error {
+	if o.DiscountPercent != nil && (*o.DiscountPercent < 0 || *o.DiscountPercent >= 100) {
+		return fmt.Errorf("скидка для '%s' должна быть от 0 до 100%%, получено %s", o.Product, formatPercent(*o.DiscountPercent))
+	}
+	if o.Price != nil && *o.Price < 0 {
+		return fmt.Errorf("ручная цена для '%s' не может быть отрицательной", o.Product)
+	}
+	return nil
+}
+
+func validateOverrides(overrides []PriceOverride) error {
+	for _, override := range overrides {
+		if err := override.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c PricingConfig) checkOptions(options GenerationOptions) error {
+	if err := validateOverrides(options.Overrides); err != nil {
+		return err
+	}
+	if options.Customer != "" && len(c.Customers) > 0 {
+		if _, ok := c.customer(options.Customer); !ok {
+			log.Printf("ПРЕДУПРЕЖДЕНИЕ: клиент '%s' не найден в настройках скидок, персональная скидка не применяется.", options.Customer)
+		}
+	}
+	return nil
+}
+
+func applyDiscount(price Money, percent float64) Money {
+	return price.ratio(int64(math.Round((100-percent)*100)), 10000)
+}
+
+func formatPercent(value float64) string {
+	return strings.Replace(strconv.FormatFloat(value, 'f', -1, 64), ".", ",", 1) + "%"
+}
+
+func (c PricingConfig) priceFor(product Product, quantity int, options GenerationOptions) priceDecision {
+	for _, override := range options.Overrides {
+		if !productMatches(product, override.Product) {
+			continue
+		}
+		decision := priceDecision{price: product.Price}
+		switch {
+		case override.Price != nil:
+			decision.price = moneyFromFloat(*override.Price, product.Price.Currency)
+			decision.rule = "ручная цена"
+			if !product.Price.IsZero() {
+				decision.discount = math.Round((1-float64(decision.price.Kopecks)/float64(product.Price.Kopecks))*10000) / 100
+			}
+		case override.DiscountPercent != nil:
+			decision.discount = *override.DiscountPercent
+			decision.price = applyDiscount(product.Price, decision.discount)
+			decision.rule = fmt.Sprintf("ручная скидка %s", formatPercent(decision.discount))
+		default:
+			continue
+		}
+		if floor, ok := c.marginFloor(product); ok && decision.price.Kopecks < floor.Kopecks {
+			decision.rule += fmt.Sprintf(" (ниже минимальной наценки %s)", formatPercent(c.MinMarginPercent))
+			log.Printf("ПРЕДУПРЕЖДЕНИЕ: ручная цена '%s' (%s) ниже минимальной: %s", product.Name, decision.price, floor)
+		}
+		return decision
+	}
+
+	decision := priceDecision{price: product.Price}
+	if customer, ok := c.customer(options.Customer); ok && customer.DiscountPercent > 0 {
+		decision.discount = customer.DiscountPercent
+		decision.rule = fmt.Sprintf("скидка клиента «%s» %s", customer.Name, formatPercent(customer.DiscountPercent))
+	}
+	for _, volume := range c.VolumeBreaks {
+		if volume.DiscountPercent > decision.discount && volume.appliesTo(product, quantity) {
+			decision.discount = volume.DiscountPercent
+			decision.rule = fmt.Sprintf("объемная скидка %s от %d %s", formatPercent(volume.DiscountPercent), volume.MinQuantity, product.Unit)
+		}
+	}
+	if decision.discount == 0 {
+		return decision
+	}
+	decision.price = applyDiscount(product.Price, decision.discount)
+	if floor, ok := c.marginFloor(product); ok && decision.price.Kopecks < floor.Kopecks {
+		decision.price = floor
+		decision.discount = math.Round((1-float64(floor.Kopecks)/float64(product.Price.Kopecks))*10000) / 100
+		decision.rule += fmt.Sprintf(", ограничена минимальной наценкой %s", formatPercent(c.MinMarginPercent))
+	}
+	return decision
+}
+
+func (c PricingConfig) marginFloor(product Product) (Money, bool) {
+	if product.Cost == nil || c.MinMarginPercent <= 0 {
+		return Money{}, false
+	}
+	floor := product.Cost.ratio(int64(math.Round((100+c.MinMarginPercent)*100)), 10000)
+	if floor.Kopecks > product.Price.Kopecks {
+		floor = product.Price
+	}
+	return floor, true
+}
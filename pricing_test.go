@@ -0,0 +1,64 @@
+package main
+
+import "testing"
+
+func TestPricingConfigPriceFor(t *testing.T) {
+	cost := Money{Kopecks: 8000, Currency: "RUB"}
+	product := Product{ID: 7, Article: "LP-100", Name: "Лоток", Unit: "м", Category: "Лотки / Перфорированные", Price: Money{Kopecks: 10000, Currency: "RUB"}, Cost: &cost}
+	price := func(v float64) *float64 { return &v }
+	config := PricingConfig{
+		Customers:        []CustomerDiscount{{Name: "ООО Ромашка", DiscountPercent: 5}},
+		VolumeBreaks:     []VolumeBreak{{Category: "лотки", MinQuantity: 100, DiscountPercent: 10}, {Product: "LP-100", MinQuantity: 500, DiscountPercent: 30}},
+		MinMarginPercent: 10,
+	}
+
+	tests := []struct {
+		name         string
+		quantity     int
+		options      GenerationOptions
+		wantPrice    int64
+		wantDiscount float64
+		wantRule     string
+	}{
+		{"без скидок", 10, GenerationOptions{}, 10000, 0, ""},
+		{"скидка клиента", 10, GenerationOptions{Customer: " ооо ромашка "}, 9500, 5, "скидка клиента «ООО Ромашка» 5%"},
+		{"объемная скидка больше клиентской", 100, GenerationOptions{Customer: "ООО Ромашка"}, 9000, 10, "объемная скидка 10% от 100 м"},
+		{"ограничение минимальной наценкой", 500, GenerationOptions{}, 8800, 12, "объемная скидка 30% от 500 м, ограничена минимальной наценкой 10%"},
+		{"ручная цена по артикулу", 10, GenerationOptions{Overrides: []PriceOverride{{Product: "lp-100", Price: price(95.5)}}}, 9550, 4.5, "ручная цена"},
+		{"ручная скидка по ID", 10, GenerationOptions{Overrides: []PriceOverride{{Product: "7", DiscountPercent: price(2.5)}}}, 9750, 2.5, "ручная скидка 2,5%"},
+		{"ручная цена ниже наценки", 10, GenerationOptions{Overrides: []PriceOverride{{Product: "7", Price: price(50)}}}, 5000, 50, "ручная цена (ниже минимальной наценки 10%)"},
+		{"ручная цена другого товара", 10, GenerationOptions{Overrides: []PriceOverride{{Product: "8", Price: price(1)}}}, 10000, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := config.priceFor(product, tt.quantity, tt.options)
+			if got.price.Kopecks != tt.wantPrice || got.discount != tt.wantDiscount || got.rule != tt.wantRule {
+				t.Errorf("priceFor = {%d, %v, %q}, ожидалось {%d, %v, %q}", got.price.Kopecks, got.discount, got.rule, tt.wantPrice, tt.wantDiscount, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestPricingConfigCheckOptions(t *testing.T) {
+	percent := func(v float64) *float64 { return &v }
+	config := PricingConfig{Customers: []CustomerDiscount{{Name: "ООО Ромашка", DiscountPercent: 5}}}
+	tests := []struct {
+		name    string
+		options GenerationOptions
+		wantErr bool
+	}{
+		{"без переопределений", GenerationOptions{Customer: "ООО Ромашка"}, false},
+		{"неизвестный клиент только предупреждает", GenerationOptions{Customer: "ООО Лютик"}, false},
+		{"допустимая скидка", GenerationOptions{Overrides: []PriceOverride{{Product: "7", DiscountPercent: percent(99.9)}}}, false},
+		{"скидка 100%", GenerationOptions{Overrides: []PriceOverride{{Product: "7", DiscountPercent: percent(100)}}}, true},
+		{"отрицательная скидка", GenerationOptions{Overrides: []PriceOverride{{Product: "7", DiscountPercent: percent(-1)}}}, true},
+		{"отрицательная цена", GenerationOptions{Overrides: []PriceOverride{{Product: "7", Price: percent(-1)}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := config.checkOptions(tt.options); (err != nil) != tt.wantErr {
+				t.Errorf("checkOptions = %v, ожидалась ошибка: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"log"
+)
+
+const (
+	generationProgressEvent = "generation:progress"
+	generationStreamEvent   = "generation:stream"
+)
+
+const (
+	ProgressLoading    = "loading"
+	ProgressKeywords   = "keywords"
+	ProgressRetrieval  = "retrieval"
+	ProgressPlanning   = "planning"
+	ProgressFormatting = "formatting"
+	ProgressPricing    = "pricing"
+	ProgressRendering  = "rendering"
+	ProgressDone       = "done"
+	ProgressFailed     = "failed"
+	ProgressCanceled   = "canceled"
+)
+
+type GenerationProgress struct {
+	JobID    string      `json:"jobId"`
+	Stage    string      `json:"stage"`
+	Percent  int         `json:"percent"`
+	Message  string      `json:"message"`
+	Artifact interface{} `json:"artifact,omitempty"`
+}
+
+type GenerationStreamDelta struct {
+	JobID    string `json:"jobId"`
+	Stage    string `json:"stage"`
+	Provider string `json:"provider"`
+	Delta    string `json:"delta"`
+	Reset    bool   `json:"reset"`
+}
+
+type progressReporter interface {
+	Progress(stage string, percent int, message string, artifact interface{})
+	Stream(stage string, delta StreamDelta)
+}
+
+type progressContextKey struct{}
+
+func withProgressReporter(ctx context.Context, reporter progressReporter) context.Context {
+	return context.WithValue(ctx, progressContextKey{}, reporter)
+}
+
+func reportProgress(ctx context.Context, stage string, percent int, message string, artifact interface{}) {
+	reporter, ok := ctx.Value(progressContextKey{}).(progressReporter)
+	if !ok {
+		return
+	}
+	reporter.Progress(stage, percent, message, artifact)
+}
+
+func reportStream(ctx context.Context, stage string, delta StreamDelta) {
+	reporter, ok := ctx.Value(progressContextKey{}).(progressReporter)
+	if !ok {
+		return
+	}
+	reporter.Stream(stage, delta)
+}
+
+func hasProgressReporter(ctx context.Context) bool {
+	_, ok := ctx.Value(progressContextKey{}).(progressReporter)
+	return ok
+}
+
+type jobReporter struct {
+	app   *App
+	jobID string
+}
+
+func (r jobReporter) Progress(stage string, percent int, message string, artifact interface{}) {
+	log.Printf("[%s] %s (%d%%): %s", r.jobID, stage, percent, message)
+	r.app.emit(generationProgressEvent, GenerationProgress{
+		JobID:    r.jobID,
+		Stage:    stage,
+		Percent:  percent,
+		Message:  message,
+		Artifact: artifact,
+	})
+}
+
+func (r jobReporter) Stream(stage string, delta StreamDelta) {
+	r.app.emit(generationStreamEvent, GenerationStreamDelta{
+		JobID:    r.jobID,
+		Stage:    stage,
+		Provider: delta.Provider,
+		Delta:    delta.Content,
+		Reset:    delta.Reset,
+	})
+}
+
+func (a *App) emit(eventName string, data ...interface{}) {
+	if a.eventSink == nil {
+		return
+	}
+	a.eventSink(eventName, data...)
+}
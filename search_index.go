@@ -0,0 +1,140 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	nameFieldBoost     = 3.0
+	articleFieldBoost  = 5.0
+	categoryFieldBoost = 1.0
+)
+
+type indexPosting struct {
+	doc    int
+	weight float64
+}
+
+type productIndex struct {
+	products  []Product
+	postings  map[string][]indexPosting
+	docLength []float64
+	avgLength float64
+	fuzzy     *trigramIndex
+}
+
+type scoredProduct struct {
+	Product Product
+	Score   float64
+}
+
+func indexTerms(text string) []string {
+	tokens := tokenize(text)
+	terms := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if term := stemRussian(token); term != "" {
+			terms = append(terms, term)
+		}
+	}
+	return append(terms, technicalTerms(text)...)
+}
+
+func buildProductIndex(products []Product) *productIndex {
+	index := &productIndex{
+		products:  products,
+		postings:  make(map[string][]indexPosting),
+		docLength: make([]float64, len(products)),
+	}
+	total := 0.0
+	for doc, product := range products {
+		weights := make(map[string]float64)
+		length := 0.0
+		for _, field := range []struct {
+			text  string
+			boost float64
+		}{
+			{product.Name, nameFieldBoost},
+			{product.Article, articleFieldBoost},
+			{product.Category, categoryFieldBoost},
+		} {
+			for _, term := range indexTerms(field.text) {
+				weights[term] += field.boost
+				length += field.boost
+			}
+		}
+		for term, weight := range weights {
+			index.postings[term] = append(index.postings[term], indexPosting{doc: doc, weight: weight})
+		}
+		index.docLength[doc] = length
+		total += length
+	}
+	if len(products) > 0 {
+		index.avgLength = total / float64(len(products))
+	}
+	var vocabulary []string
+	for term := range index.postings {
+		if !strings.ContainsRune(term, ':') {
+			vocabulary = append(vocabulary, term)
+		}
+	}
+	sort.Strings(vocabulary)
+	index.fuzzy = buildTrigramIndex(vocabulary)
+	return index
+}
+
+func (idx *productIndex) idf(term string) float64 {
+	n := float64(len(idx.products))
+	df := float64(len(idx.postings[term]))
+	return math.Log(1 + (n-df+0.5)/(df+0.5))
+}
+
+func (idx *productIndex) search(queryTerms []string, topK int) []scoredProduct {
+	if idx == nil || len(idx.products) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(queryTerms))
+	scores := make(map[int]float64)
+	for _, query := range idx.expandQuery(queryTerms) {
+		term := query.term
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		postings := idx.postings[term]
+		idf := idx.idf(term) * termBoost(term) * query.weight
+		for _, posting := range postings {
+			norm := 1 - bm25B + bm25B*idx.docLength[posting.doc]/idx.avgLength
+			scores[posting.doc] += idf * posting.weight * (bm25K1 + 1) / (posting.weight + bm25K1*norm)
+		}
+	}
+	results := make([]scoredProduct, 0, len(scores))
+	for doc, score := range scores {
+		results = append(results, scoredProduct{Product: idx.products[doc], Score: score})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if len(results[i].Product.Name) != len(results[j].Product.Name) {
+			return len(results[i].Product.Name) < len(results[j].Product.Name)
+		}
+		return results[i].Product.ID < results[j].Product.ID
+	})
+	if topK > 0 && len(results) > topK {
+		results = results[:topK]
+	}
+	return results
+}
+
+func queryTerms(keywords []string) []string {
+	var terms []string
+	for _, keyword := range keywords {
+		terms = append(terms, indexTerms(strings.ToLower(keyword))...)
+	}
+	return terms
+}
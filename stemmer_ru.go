@@ -0,0 +1,125 @@
+package main
+
+import "strings"
+
+var (
+	ruPerfectiveGerund1 = []string{"вшись", "вши", "в"}
+	ruPerfectiveGerund2 = []string{"ившись", "ывшись", "ивши", "ывши", "ив", "ыв"}
+	ruAdjective         = []string{"ими", "ыми", "его", "ого", "ему", "ому", "ее", "ие", "ые", "ое", "ей", "ий", "ый", "ой", "ем", "им", "ым", "ом", "их", "ых", "ую", "юю", "ая", "яя", "ою", "ею"}
+	ruParticiple1       = []string{"ем", "нн", "вш", "ющ", "щ"}
+	ruParticiple2       = []string{"ивш", "ывш", "ующ"}
+	ruReflexive         = []string{"ся", "сь"}
+	ruVerb1             = []string{"ете", "йте", "ешь", "нно", "ла", "на", "ли", "ем", "ло", "но", "ет", "ют", "ны", "ть", "й", "л", "н"}
+	ruVerb2             = []string{"ейте", "уйте", "ила", "ыла", "ена", "ите", "или", "ыли", "ило", "ыло", "ено", "ует", "уют", "ены", "ить", "ыть", "ишь", "ей", "уй", "ил", "ыл", "им", "ым", "ен", "ят", "ит", "ыт", "ую", "ю"}
+	ruNoun              = []string{"иями", "ями", "ами", "ией", "иям", "ием", "иях", "ев", "ов", "ие", "ье", "еи", "ии", "ей", "ой", "ий", "ям", "ем", "ам", "ом", "ах", "ях", "ию", "ью", "ия", "ья", "а", "е", "и", "й", "о", "у", "ы", "ь", "ю", "я"}
+	ruSuperlative       = []string{"ейше", "ейш"}
+	ruDerivational      = []string{"ость", "ост"}
+)
+
+func isRussianVowel(r rune) bool {
+	return strings.ContainsRune("аеиоуыэюя", r)
+}
+
+func russianRegions(word []rune) (int, int) {
+	rv := len(word)
+	for i, r := range word {
+		if isRussianVowel(r) {
+			rv = i + 1
+			break
+		}
+	}
+	r1 := len(word)
+	for i := 1; i < len(word); i++ {
+		if !isRussianVowel(word[i]) && isRussianVowel(word[i-1]) {
+			r1 = i + 1
+			break
+		}
+	}
+	r2 := len(word)
+	for i := r1 + 1; i < len(word); i++ {
+		if !isRussianVowel(word[i]) && isRussianVowel(word[i-1]) {
+			r2 = i + 1
+			break
+		}
+	}
+	return rv, r2
+}
+
+func matchRussianSuffix(word []rune, start int, suffixes []string) int {
+	for _, suffix := range suffixes {
+		s := []rune(suffix)
+		if len(word)-len(s) < start || string(word[len(word)-len(s):]) != suffix {
+			continue
+		}
+		return len(s)
+	}
+	return 0
+}
+
+func matchRussianSuffixAfterAYa(word []rune, start int, suffixes []string) int {
+	for _, suffix := range suffixes {
+		s := []rune(suffix)
+		pos := len(word) - len(s)
+		if pos-1 < start || string(word[pos:]) != suffix {
+			continue
+		}
+		if word[pos-1] == 'а' || word[pos-1] == 'я' {
+			return len(s)
+		}
+	}
+	return 0
+}
+
+func longestRussianSuffix(word []rune, start int, group1, group2 []string) int {
+	n1 := matchRussianSuffixAfterAYa(word, start, group1)
+	n2 := matchRussianSuffix(word, start, group2)
+	if n1 > n2 {
+		return n1
+	}
+	return n2
+}
+
+func stemRussian(word string) string {
+	runes := []rune(strings.ReplaceAll(strings.ToLower(word), "ё", "е"))
+	rv, r2 := russianRegions(runes)
+	if rv >= len(runes) {
+		return string(runes)
+	}
+
+	if n := longestRussianSuffix(runes, rv, ruPerfectiveGerund1, ruPerfectiveGerund2); n > 0 {
+		runes = runes[:len(runes)-n]
+	} else {
+		if n := matchRussianSuffix(runes, rv, ruReflexive); n > 0 {
+			runes = runes[:len(runes)-n]
+		}
+		if n := matchRussianSuffix(runes, rv, ruAdjective); n > 0 {
+			runes = runes[:len(runes)-n]
+			if n := longestRussianSuffix(runes, rv, ruParticiple1, ruParticiple2); n > 0 {
+				runes = runes[:len(runes)-n]
+			}
+		} else if n := longestRussianSuffix(runes, rv, ruVerb1, ruVerb2); n > 0 {
+			runes = runes[:len(runes)-n]
+		} else if n := matchRussianSuffix(runes, rv, ruNoun); n > 0 {
+			runes = runes[:len(runes)-n]
+		}
+	}
+
+	if len(runes) > rv && runes[len(runes)-1] == 'и' {
+		runes = runes[:len(runes)-1]
+	}
+	if n := matchRussianSuffix(runes, r2, ruDerivational); n > 0 {
+		runes = runes[:len(runes)-n]
+	}
+	switch {
+	case matchRussianSuffix(runes, rv, []string{"нн"}) > 0:
+		runes = runes[:len(runes)-1]
+	case matchRussianSuffix(runes, rv, ruSuperlative) > 0:
+		runes = runes[:len(runes)-matchRussianSuffix(runes, rv, ruSuperlative)]
+		if matchRussianSuffix(runes, rv, []string{"нн"}) > 0 {
+			runes = runes[:len(runes)-1]
+		}
+	case matchRussianSuffix(runes, rv, []string{"ь"}) > 0:
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes)
+}
@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestStemRussian(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"кабельные", "кабельн"},
+		{"каналы", "канал"},
+		{"лотки", "лотк"},
+		{"лоток", "лоток"},
+		{"гайками", "гайк"},
+		{"крышка", "крышк"},
+		{"крышки", "крышк"},
+		{"перфорированный", "перфорирова"},
+		{"болтов", "болт"},
+		{"монтажа", "монтаж"},
+		{"м10", "м10"},
+	}
+	for _, tt := range tests {
+		if got := stemRussian(tt.word); got != tt.want {
+			t.Errorf("stemRussian(%q) = %q, ожидалось %q", tt.word, got, tt.want)
+		}
+	}
+}
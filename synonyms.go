@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+const synonymsFile = "synonyms.json"
+
+type SynonymDictionary struct {
+	Groups        [][]string        `json:"groups"`
+	Abbreviations map[string]string `json:"abbreviations"`
+}
+
+func normalizeSynonymPhrase(phrase string) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(strings.ToLower(phrase), "ё", "е")), " ")
+}
+
+func normalizeAbbreviation(abbreviation string) string {
+	return strings.TrimSuffix(normalizeSynonymPhrase(abbreviation), ".")
+}
+
+func (d SynonymDictionary) normalized() (SynonymDictionary, error) {
+	result := SynonymDictionary{Groups: [][]string{}, Abbreviations: make(map[string]string)}
+	for _, group := range d.Groups {
+		seen := make(map[string]bool)
+		var terms []string
+		for _, term := range group {
+			term = normalizeSynonymPhrase(term)
+			if term == "" || seen[term] {
+				continue
+			}
+			seen[term] = true
+			terms = append(terms, term)
+		}
+		if len(terms) > 1 {
+			result.Groups = append(result.Groups, terms)
+		}
+	}
+	for abbreviation, expansion := range d.Abbreviations {
+		key := normalizeAbbreviation(abbreviation)
+		value := normalizeSynonymPhrase(expansion)
+		if key == "" || value == "" {
+			return SynonymDictionary{}, fmt.Errorf("сокращение '%s' должно иметь непустую расшифровку", abbreviation)
+		}
+		if strings.Contains(key, " ") {
+			return SynonymDictionary{}, fmt.Errorf("сокращение '%s' должно состоять из одного слова", abbreviation)
+		}
+		result.Abbreviations[key] = value
+	}
+	return result, nil
+}
+
+func loadSynonyms() (SynonymDictionary, error) {
+	data, err := os.ReadFile(synonymsFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return SynonymDictionary{Groups: [][]string{}, Abbreviations: make(map[string]string)}, nil
+	}
+	if err != nil {
+		return SynonymDictionary{}, fmt.Errorf("не удалось прочитать '%s': %w", synonymsFile, err)
+	}
+	var dictionary SynonymDictionary
+	if err := json.Unmarshal(data, &dictionary); err != nil {
+		return SynonymDictionary{}, fmt.Errorf("файл '%s' поврежден: %w", synonymsFile, err)
+	}
+	return dictionary.normalized()
+}
+
+func (d SynonymDictionary) save() error {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return fmt.Errorf("не удалось сформировать JSON словаря синонимов: %w", err)
+	}
+	if err := os.WriteFile(synonymsFile, data, 0644); err != nil {
+		return fmt.Errorf("не удалось записать '%s': %w", synonymsFile, err)
+	}
+	return nil
+}
+
+func (d SynonymDictionary) expand(texts []string) []string {
+	tokens := make(map[string]bool)
+	words := make(map[string]bool)
+	addText := func(text string) {
+		for _, token := range tokenize(text) {
+			tokens[token] = true
+			words[token] = true
+			words[stemRussian(token)] = true
+		}
+	}
+	for _, text := range texts {
+		addText(text)
+	}
+
+	seen := make(map[string]bool)
+	var expansions []string
+	addExpansion := func(phrase string) {
+		if !seen[phrase] {
+			seen[phrase] = true
+			expansions = append(expansions, phrase)
+		}
+	}
+	for abbreviation, expansion := range d.Abbreviations {
+		if tokens[abbreviation] {
+			addExpansion(expansion)
+		}
+	}
+	for _, expansion := range expansions {
+		addText(expansion)
+	}
+	for _, group := range d.Groups {
+		matched := false
+		for _, term := range group {
+			if containsPhrase(words, term) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		for _, term := range group {
+			addExpansion(term)
+		}
+	}
+	sort.Strings(expansions)
+	return expansions
+}
+
+func containsPhrase(words map[string]bool, phrase string) bool {
+	tokens := tokenize(phrase)
+	if len(tokens) == 0 {
+		return false
+	}
+	for _, token := range tokens {
+		if !words[token] && !words[stemRussian(token)] {
+			return false
+		}
+	}
+	return true
+}
+
+func (d SynonymDictionary) promptSection() string {
+	if len(d.Groups) == 0 && len(d.Abbreviations) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("СЛОВАРЬ ПРЕДПРИЯТИЯ (если в запросе есть сокращение или синоним из словаря, добавь в ключевые слова его расшифровку и синонимы):\n")
+	if len(d.Groups) > 0 {
+		sb.WriteString("Синонимы (слова в строке означают одно и то же):\n")
+		for _, group := range d.Groups {
+			sb.WriteString("- " + strings.Join(group, " = ") + "\n")
+		}
+	}
+	if len(d.Abbreviations) > 0 {
+		abbreviations := make([]string, 0, len(d.Abbreviations))
+		for abbreviation := range d.Abbreviations {
+			abbreviations = append(abbreviations, abbreviation)
+		}
+		sort.Strings(abbreviations)
+		sb.WriteString("Сокращения:\n")
+		for _, abbreviation := range abbreviations {
+			sb.WriteString(fmt.Sprintf("- %s — %s\n", abbreviation, d.Abbreviations[abbreviation]))
+		}
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+func (a *App) synonymDictionary() SynonymDictionary {
+	a.synonymsMutex.RLock()
+	defer a.synonymsMutex.RUnlock()
+	return a.synonyms
+}
+
+func (a *App) GetSynonyms() SynonymDictionary {
+	return a.synonymDictionary()
+}
+
+func (a *App) SaveSynonyms(dictionary SynonymDictionary) (SynonymDictionary, error) {
+	normalized, err := dictionary.normalized()
+	if err != nil {
+		return SynonymDictionary{}, err
+	}
+	if err := normalized.save(); err != nil {
+		return SynonymDictionary{}, err
+	}
+	a.synonymsMutex.Lock()
+	a.synonyms = normalized
+	a.synonymsMutex.Unlock()
+	log.Printf("Словарь синонимов сохранен: %d групп, %d сокращений.", len(normalized.Groups), len(normalized.Abbreviations))
+	return normalized, nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestSynonymDictionaryNormalized(t *testing.T) {
+	dictionary, err := SynonymDictionary{
+		Groups: [][]string{
+			{" Короб ", "кабель  канал", "короб"},
+			{"Трей"},
+			{"Ёрш", "ерш", "щетка"},
+		},
+		Abbreviations: map[string]string{"МТЛ.": " Металлический ", "оц": "оцинкованный"},
+	}.normalized()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantGroups := [][]string{{"короб", "кабель канал"}, {"ерш", "щетка"}}
+	if !reflect.DeepEqual(dictionary.Groups, wantGroups) {
+		t.Errorf("группы = %q, ожидалось %q", dictionary.Groups, wantGroups)
+	}
+	wantAbbreviations := map[string]string{"мтл": "металлический", "оц": "оцинкованный"}
+	if !reflect.DeepEqual(dictionary.Abbreviations, wantAbbreviations) {
+		t.Errorf("сокращения = %q, ожидалось %q", dictionary.Abbreviations, wantAbbreviations)
+	}
+
+	invalid := []map[string]string{
+		{"мтл": " "},
+		{" ": "металлический"},
+		{"к к": "кабель-канал"},
+	}
+	for _, abbreviations := range invalid {
+		if _, err := (SynonymDictionary{Abbreviations: abbreviations}).normalized(); err == nil {
+			t.Errorf("сокращения %q должны отклоняться", abbreviations)
+		}
+	}
+}
+
+func TestSynonymDictionaryExpand(t *testing.T) {
+	dictionary, err := SynonymDictionary{
+		Groups: [][]string{
+			{"короб", "кабель канал"},
+			{"металлический", "стальной"},
+			{"лоток", "трей"},
+		},
+		Abbreviations: map[string]string{"мтл": "металлический", "пвх": "поливинилхлорид"},
+	}.normalized()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		texts []string
+		want  []string
+	}{
+		{"сокращение и группа через расшифровку", []string{"мтл 100х100"}, []string{"металлический", "стальной"}},
+		{"словоформа члена группы", []string{"нужны короба"}, []string{"кабель канал", "короб"}},
+		{"фраза из нескольких слов", []string{"кабель канал 40х25"}, []string{"кабель канал", "короб"}},
+		{"несколько текстов", []string{"трей", "пвх"}, []string{"лоток", "поливинилхлорид", "трей"}},
+		{"часть фразы не срабатывает", []string{"кабель ВВГ"}, nil},
+		{"сокращение внутри слова не срабатывает", []string{"мтлх"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dictionary.expand(tt.texts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expand(%q) = %q, ожидалось %q", tt.texts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveSynonymsRoundTrip(t *testing.T) {
+	chdirTemp(t)
+	app := newTestApp(testCatalog(), &fakeLLMProvider{name: "fake"})
+
+	saved, err := app.SaveSynonyms(SynonymDictionary{
+		Groups:        [][]string{{"Короб", "кабель-канал"}},
+		Abbreviations: map[string]string{"МТЛ.": "металлический"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(app.GetSynonyms(), saved) {
+		t.Errorf("GetSynonyms = %+v, ожидалось %+v", app.GetSynonyms(), saved)
+	}
+	if _, err := os.Stat(synonymsFile); err != nil {
+		t.Fatalf("словарь не записан: %v", err)
+	}
+	loaded, err := loadSynonyms()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, saved) {
+		t.Errorf("после перечитывания = %+v, ожидалось %+v", loaded, saved)
+	}
+
+	if _, err := app.SaveSynonyms(SynonymDictionary{Abbreviations: map[string]string{"мтл": ""}}); err == nil {
+		t.Fatal("словарь с пустой расшифровкой должен отклоняться")
+	}
+	if !reflect.DeepEqual(app.GetSynonyms(), saved) {
+		t.Error("неудачное сохранение не должно менять текущий словарь")
+	}
+}
+
+func TestLoadSynonymsMissingAndCorrupt(t *testing.T) {
+	chdirTemp(t)
+	dictionary, err := loadSynonyms()
+	if err != nil || len(dictionary.Groups) != 0 || len(dictionary.Abbreviations) != 0 {
+		t.Fatalf("без файла ожидался пустой словарь: %+v, %v", dictionary, err)
+	}
+	if err := os.WriteFile(synonymsFile, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadSynonyms(); err == nil {
+		t.Error("поврежденный файл должен возвращать ошибку")
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	termDimension     = "dim:"
+	termDimensionSet  = "dimset:"
+	termDimensionPart = "dimpart:"
+	termThread        = "thr:"
+	termLength        = "len:"
+)
+
+var (
+	threadPattern    = regexp.MustCompile(`(?i)[мm](\d+(?:[.,]\d+)?)(?:\s*[хx×*]\s*(\d+(?:[.,]\d+)?))?`)
+	dimensionPattern = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s*[хx×*]\s*(\d+(?:[.,]\d+)?)(?:\s*[хx×*]\s*(\d+(?:[.,]\d+)?))?(?:\s*(мм|см|м|mm|cm|m)(?:[^\p{L}\d]|$))?`)
+	lengthPattern    = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s*(мм|миллиметр\p{L}*|см|сантиметр\p{L}*|метр\p{L}*|м|mm|cm|m)(?:[^\p{L}\d]|$)`)
+)
+
+var techTermBoosts = map[string]float64{
+	termDimension:     6,
+	termDimensionSet:  3,
+	termThread:        5,
+	termLength:        2,
+	termDimensionPart: 0.5,
+}
+
+func termBoost(term string) float64 {
+	if idx := strings.IndexByte(term, ':'); idx > 0 {
+		if boost, ok := techTermBoosts[term[:idx+1]]; ok {
+			return boost
+		}
+	}
+	return 1
+}
+
+func canonicalNumber(value string) (float64, string, bool) {
+	number, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return number, strconv.FormatFloat(number, 'f', -1, 64), true
+}
+
+func millimetres(value float64, unit string) float64 {
+	unit = strings.ToLower(unit)
+	switch {
+	case unit == "" || unit == "мм" || unit == "mm" || strings.HasPrefix(unit, "миллиметр"):
+		return value
+	case unit == "см" || unit == "cm" || strings.HasPrefix(unit, "сантиметр"):
+		return value * 10
+	default:
+		return value * 1000
+	}
+}
+
+func precededByWordChar(text string, start int) bool {
+	if start == 0 {
+		return false
+	}
+	r, _ := utf8.DecodeLastRuneInString(text[:start])
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func precededByDimensionSeparator(text string, start int) bool {
+	before := strings.TrimRightFunc(text[:start], unicode.IsSpace)
+	r, _ := utf8.DecodeLastRuneInString(before)
+	return strings.ContainsRune("хХxX×*", r)
+}
+
+func dimensionSet(parts []string) string {
+	sorted := append([]string(nil), parts...)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, _ := strconv.ParseFloat(sorted[i], 64)
+		b, _ := strconv.ParseFloat(sorted[j], 64)
+		return a < b
+	})
+	return termDimensionSet + strings.Join(sorted, "x")
+}
+
+func technicalTerms(text string) []string {
+	text = strings.ToLower(text)
+	var terms []string
+	for _, m := range threadPattern.FindAllStringSubmatchIndex(text, -1) {
+		if precededByWordChar(text, m[0]) {
+			continue
+		}
+		_, diameter, ok := canonicalNumber(text[m[2]:m[3]])
+		if !ok {
+			continue
+		}
+		terms = append(terms, termThread+"m"+diameter)
+		if m[4] >= 0 {
+			if _, length, ok := canonicalNumber(text[m[4]:m[5]]); ok {
+				terms = append(terms, termLength+length)
+			}
+		}
+	}
+	for _, m := range dimensionPattern.FindAllStringSubmatchIndex(text, -1) {
+		if precededByWordChar(text, m[0]) {
+			continue
+		}
+		unit := ""
+		if m[8] >= 0 {
+			unit = text[m[8]:m[9]]
+		}
+		var parts []string
+		for group := 1; group <= 3; group++ {
+			if m[group*2] < 0 {
+				continue
+			}
+			value, _, ok := canonicalNumber(text[m[group*2]:m[group*2+1]])
+			if !ok {
+				parts = nil
+				break
+			}
+			parts = append(parts, strconv.FormatFloat(millimetres(value, unit), 'f', -1, 64))
+		}
+		if len(parts) < 2 {
+			continue
+		}
+		terms = append(terms, termDimension+strings.Join(parts, "x"))
+		terms = append(terms, dimensionSet(parts))
+		if len(parts) == 3 {
+			terms = append(terms, dimensionSet(parts[:2]), dimensionSet(parts[1:]), dimensionSet([]string{parts[0], parts[2]}))
+		}
+		for _, part := range parts {
+			terms = append(terms, termDimensionPart+part)
+		}
+	}
+	for _, m := range lengthPattern.FindAllStringSubmatchIndex(text, -1) {
+		if precededByWordChar(text, m[0]) || precededByDimensionSeparator(text, m[0]) {
+			continue
+		}
+		value, _, ok := canonicalNumber(text[m[2]:m[3]])
+		if !ok {
+			continue
+		}
+		terms = append(terms, termLength+strconv.FormatFloat(millimetres(value, text[m[4]:m[5]]), 'f', -1, 64))
+	}
+	return terms
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTechnicalTerms(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Лоток 100х100 мм, 12 метров", []string{"dim:100x100", "dimset:100x100", "dimpart:100", "dimpart:100", "len:12000"}},
+		{"лоток 0,2х0,1 м", []string{"dim:200x100", "dimset:100x200", "dimpart:200", "dimpart:100"}},
+		{"короб 200x200x3000", []string{"dim:200x200x3000", "dimset:200x200x3000", "dimset:200x200", "dimset:200x3000", "dimset:200x3000", "dimpart:200", "dimpart:200", "dimpart:3000"}},
+		{"болт М10х30", []string{"thr:m10", "len:30"}},
+		{"DIN 933 M8x40", []string{"thr:m8", "len:40"}},
+		{"кабель 2,5 мм", []string{"len:2.5"}},
+		{"гайка шестигранная", nil},
+	}
+	for _, tt := range tests {
+		if got := technicalTerms(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("technicalTerms(%q) = %q, ожидалось %q", tt.text, got, tt.want)
+		}
+	}
+}
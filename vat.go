@@ -0,0 +1,59 @@
+package main
+
+import "fmt"
+
+const defaultVATRate = 20
+
+type VATConfig struct {
+	DefaultRate      *int  `json:"defaultRate"`
+	PricesIncludeVAT *bool `json:"pricesIncludeVat"`
+}
+
+func (c VATConfig) pricesIncludeVAT() bool {
+	return c.PricesIncludeVAT == nil || *c.PricesIncludeVAT
+}
+
+func (c VATConfig) rateFor(product Product) int {
+	if product.VATRate != nil {
+		return *product.VATRate
+	}
+	if c.DefaultRate != nil {
+		return *c.DefaultRate
+	}
+	return defaultVATRate
+}
+
+func (c VATConfig) applyTo(item *TCPItem, rate int) {
+	item.VATRate = rate
+	if c.pricesIncludeVAT() {
+		item.VATAmount = item.Subtotal.ratio(int64(rate), int64(100+rate))
+		item.Total = item.Subtotal
+		return
+	}
+	item.VATAmount = item.Subtotal.ratio(int64(rate), 100)
+	item.Total = item.Subtotal.Add(item.VATAmount)
+}
+
+func calculateTotals(items []TCPItem, pricesIncludeVAT bool, currency string) (FinalLogResponse, error) {
+	zero := Money{Currency: currency}
+	result := FinalLogResponse{
+		FoundItems:       items,
+		TotalCost:        zero,
+		TotalVAT:         zero,
+		PricesIncludeVAT: pricesIncludeVAT,
+		Currency:         currency,
+	}
+	var err error
+	for _, item := range items {
+		if result.TotalCost, err = result.TotalCost.checkedAdd(item.Total); err != nil {
+			return FinalLogResponse{}, fmt.Errorf("ошибка расчета итога по '%s': %w", item.Name, err)
+		}
+		if result.TotalVAT, err = result.TotalVAT.checkedAdd(item.VATAmount); err != nil {
+			return FinalLogResponse{}, fmt.Errorf("ошибка расчета НДС по '%s': %w", item.Name, err)
+		}
+	}
+	if result.TotalWithoutVAT, err = result.TotalCost.checkedSub(result.TotalVAT); err != nil {
+		return FinalLogResponse{}, err
+	}
+	return result, nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestVATConfigApplyTo(t *testing.T) {
+	tests := []struct {
+		name             string
+		pricesIncludeVAT bool
+		subtotal         int64
+		rate             int
+		wantVAT          int64
+		wantTotal        int64
+	}{
+		{"НДС в цене", true, 12000, 20, 2000, 12000},
+		{"НДС сверху", false, 10000, 20, 2000, 12000},
+		{"НДС 10% в цене", true, 1000, 10, 91, 1000},
+		{"без НДС", false, 10000, 0, 0, 10000},
+		{"округление копеек", true, 64000, 20, 10667, 64000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := TCPItem{Subtotal: Money{Kopecks: tt.subtotal, Currency: "RUB"}}
+			VATConfig{PricesIncludeVAT: &tt.pricesIncludeVAT}.applyTo(&item, tt.rate)
+			if item.VATRate != tt.rate || item.VATAmount.Kopecks != tt.wantVAT || item.Total.Kopecks != tt.wantTotal {
+				t.Errorf("ставка %d, НДС %d, итого %d; ожидалось НДС %d, итого %d", item.VATRate, item.VATAmount.Kopecks, item.Total.Kopecks, tt.wantVAT, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestVATConfigPricesIncludeVATDefault(t *testing.T) {
+	tests := []struct {
+		data string
+		want bool
+	}{
+		{`{}`, true},
+		{`{"defaultRate": 10}`, true},
+		{`{"pricesIncludeVat": true}`, true},
+		{`{"pricesIncludeVat": false}`, false},
+	}
+	for _, tt := range tests {
+		var config VATConfig
+		if err := json.Unmarshal([]byte(tt.data), &config); err != nil {
+			t.Fatal(err)
+		}
+		if got := config.pricesIncludeVAT(); got != tt.want {
+			t.Errorf("%s: цены с НДС = %v, ожидалось %v", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestCalculateTotals(t *testing.T) {
+	items := []TCPItem{
+		{Name: "Лоток", Total: Money{Kopecks: 12000, Currency: "RUB"}, VATAmount: Money{Kopecks: 2000, Currency: "RUB"}},
+		{Name: "Гайка", Total: Money{Kopecks: 600, Currency: "RUB"}, VATAmount: Money{Kopecks: 100, Currency: "RUB"}},
+	}
+	totals, err := calculateTotals(items, true, "RUB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if totals.TotalCost.Kopecks != 12600 || totals.TotalVAT.Kopecks != 2100 || totals.TotalWithoutVAT.Kopecks != 10500 {
+		t.Errorf("итоги = %s / НДС %s / без НДС %s", totals.TotalCost, totals.TotalVAT, totals.TotalWithoutVAT)
+	}
+
+	items[1].Total.Currency = "USD"
+	if _, err := calculateTotals(items, true, "RUB"); err == nil {
+		t.Error("позиция в другой валюте должна вернуть ошибку")
+	}
+}